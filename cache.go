@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/input"
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
+)
+
+// cacheFormat has to be increased whenever cacheEntry, or the way files are searched, changes
+const cacheFormat = "1"
+
+// extractionCache stores what the native engine found in each file, so that the next commands do not search them again
+// Search results are stored instead of timelines: displayers are closures, and contexts are rebuilt by running handlers again
+// on the few lines that matched, which is cheap compared to searching gigabytes of logs
+// Files are always searched with every regexes, so that any subcommand can reuse what a previous one found
+type extractionCache struct {
+	dir     string
+	version string
+	filter  *nativeFilter
+}
+
+type cacheEntry struct {
+	Version string         `json:"version"`
+	Path    string         `json:"path"`
+	Size    int64          `json:"size"`
+	ModTime time.Time      `json:"modTime"`
+	Hash    string         `json:"hash"`
+	Resume  int64          `json:"resume"` // where the search can continue when lines were appended, -1 when it can't
+	Tail    int            `json:"tail"`   // results from this index were found after Resume, they are found again when resuming
+	Results []cachedResult `json:"results"`
+}
+
+type cachedResult struct {
+	Text   string `json:"text"`
+	Record bool   `json:"record,omitempty"`
+}
+
+func newExtractionCache(dir string) (*extractionCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Wrap(err, "failed to create cache directory")
+	}
+	regexes := nativeRegexes(regex.AllRegexes())
+	filter := prepareNativeFilter(regexes)
+	return &extractionCache{dir: dir, version: cacheVersion(regexes), filter: filter}, nil
+}
+
+// cacheVersion changes whenever the content of the cache would be different: results depend on regexes and on how files are read
+// handlers are not part of it, they are run again anyway
+func cacheVersion(regexes types.RegexMap) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%t\n%d\n", cacheFormat, CLI.InputFormat, CLI.PxcOperator, maxRecordLines)
+	for _, key := range regexes.SortedKeys() {
+		fmt.Fprintf(h, "%s\n%s\n%t\n", key, regexes[key].Regex.String(), regexes[key].MultiLine)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *extractionCache) entryPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	h := sha256.Sum256([]byte(c.version + "\n" + abs))
+	return filepath.Join(c.dir, hex.EncodeToString(h[:16])+".json")
+}
+
+// search sends the results of the file, from the cache when it is still valid
+// Files that only had lines appended are searched again from the last record that was cached
+func (c *extractionCache) search(path string, stdout chan<- searchResult) error {
+	defer close(stdout)
+
+	info, err := input.Stat(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to stat %s", path)
+	}
+	// only plain text files can be read again from an offset
+	resumable := input.IsPlainFile(path) && !input.IsJournaldJSON(path, CLI.InputFormat)
+
+	entry, err := c.load(path)
+	if err != nil {
+		logger.Debug().Str("path", path).Err(err).Msg("cache miss")
+	}
+	switch {
+	case entry != nil && entry.Size == info.Size() && entry.ModTime.Equal(info.ModTime()) && c.hashMatches(path, entry):
+		logger.Debug().Str("path", path).Msg("cache hit")
+		for _, result := range entry.Results {
+			stdout <- searchResult{text: result.Text, record: result.Record}
+		}
+		return nil
+
+	case entry != nil && resumable && entry.Resume >= 0 && info.Size() > entry.Size && c.hashMatches(path, entry):
+		logger.Debug().Str("path", path).Int64("from", entry.Resume).Msg("cache hit, searching appended lines")
+		for _, result := range entry.Results[:entry.Tail] {
+			stdout <- searchResult{text: result.Text, record: result.Record}
+		}
+		entry.Results = entry.Results[:entry.Tail]
+
+	default:
+		entry = &cacheEntry{Results: []cachedResult{}}
+	}
+
+	entry.Version = c.version
+	entry.Path = path
+	entry.Size = info.Size()
+	entry.ModTime = info.ModTime()
+	entry.Hash, err = input.SampleHash(path, info.Size())
+	if err != nil {
+		return err
+	}
+
+	if err := c.scan(path, entry, resumable, stdout); err != nil {
+		return err
+	}
+	return c.save(path, entry)
+}
+
+func (c *extractionCache) hashMatches(path string, entry *cacheEntry) bool {
+	hash, err := input.SampleHash(path, entry.Size)
+	return err == nil && hash == entry.Hash
+}
+
+// scan searches the file from entry.Resume, adding every results to the entry
+// It is execNativeAndIterate, keeping track of where the last record started
+func (c *extractionCache) scan(path string, entry *cacheEntry, resumable bool, stdout chan<- searchResult) error {
+	f, err := input.OpenFormat(path, CLI.InputFormat)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", path)
+	}
+	defer f.Close()
+
+	offset := int64(0)
+	if resumable && entry.Resume > 0 {
+		if _, err := io.CopyN(io.Discard, f, entry.Resume); err != nil {
+			return errors.Wrapf(err, "failed to skip cached content of %s", path)
+		}
+		offset = entry.Resume
+	}
+
+	var previousRecord bool
+	emit := func(result searchResult) {
+		previousRecord = previousRecord || result.record
+		entry.Results = append(entry.Results, cachedResult{Text: result.text, Record: result.record})
+		stdout <- result
+	}
+	assembler := &recordAssembler{filter: c.filter}
+	entry.Resume, entry.Tail = offset, len(entry.Results)
+
+	r := bufio.NewReader(f)
+	for {
+		chunk, err := r.ReadString('\n')
+		if len(chunk) > 0 {
+			line := chunk
+			if line[len(line)-1] == '\n' {
+				line = line[:len(line)-1]
+			}
+			before := len(entry.Results)
+			previousRecord = false
+			if assembler.feed(line, emit) {
+				// results of the record that just ended were sent first
+				entry.Resume, entry.Tail = offset, before
+				if previousRecord {
+					entry.Tail++
+				}
+			}
+			offset += int64(len(chunk))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to read %s", path)
+		}
+	}
+	assembler.flush(emit)
+
+	if !resumable {
+		entry.Resume = -1
+	}
+	return nil
+}
+
+func (c *extractionCache) load(path string) (*cacheEntry, error) {
+	b, err := os.ReadFile(c.entryPath(path))
+	if err != nil {
+		return nil, err
+	}
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(b, entry); err != nil {
+		return nil, errors.Wrap(err, "invalid cache entry")
+	}
+	if entry.Version != c.version || entry.Tail > len(entry.Results) {
+		return nil, errors.New("outdated cache entry")
+	}
+	return entry, nil
+}
+
+// save writes in a temporary file first, so that concurrent runs never read partial entries
+func (c *extractionCache) save(path string, entry *cacheEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "failed to serialize cache entry")
+	}
+	tmp, err := os.CreateTemp(c.dir, ".entry-*")
+	if err != nil {
+		return errors.Wrap(err, "failed to write cache entry")
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "failed to write cache entry")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed to write cache entry")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), c.entryPath(path)), "failed to write cache entry")
+}
@@ -3,22 +3,31 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
 	"github.com/ylacancellera/galera-log-explainer/utils"
 	"gopkg.in/yaml.v2"
 )
 
 type conflicts struct {
-	Paths []string `arg:"" name:"paths" help:"paths of the log to use"`
-	Yaml  bool     `xor:"format"`
-	Json  bool     `xor:"format"`
+	Paths         []string      `arg:"" name:"paths" help:"paths of the log to use"`
+	Yaml          bool          `xor:"format"`
+	Json          bool          `xor:"format"`
+	Certification bool          `help:"Summarize certification failures and brute force aborts instead: counters per node, hot periods, and whose writes lose most often. Needs wsrep_log_conflicts"`
+	Period        time.Duration `default:"1m" help:"Length of the hot periods, with --certification"`
 }
 
 func (c *conflicts) Help() string {
 	return "Summarize every replication conflicts, from every node's point of view"
 }
 
+// hotPeriodsToShow are the periods with the most conflicts
+const hotPeriodsToShow = 10
+
 func (c *conflicts) Run() error {
 
 	regexes := regex.IdentsMap.Merge(regex.ApplicativeMap)
@@ -28,45 +37,151 @@ func (c *conflicts) Run() error {
 	}
 
 	ctxs := timeline.GetLatestUpdatedContextsByNodes()
-	for _, ctx := range ctxs {
-		if len(ctx.Conflicts) == 0 {
-			continue
+	if c.Certification {
+		return c.printCertification(types.NewCertificationReport(ctxs, c.Period, hotPeriodsToShow))
+	}
+	conflicts := collectConflicts(ctxs)
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	var out string
+	if c.Yaml {
+		tmp, err := yaml.Marshal(conflicts)
+		if err != nil {
+			return err
+		}
+		out = string(tmp)
+	} else if c.Json {
+		tmp, err := json.Marshal(conflicts)
+		if err != nil {
+			return err
 		}
-		var out string
+		out = string(tmp)
+	} else {
 
-		if c.Yaml {
-			tmp, err := yaml.Marshal(ctx.Conflicts)
-			if err != nil {
-				return err
+		for _, conflict := range conflicts {
+			out += "\n"
+			out += "\n" + utils.Paint(utils.BlueText, "seqno: ") + conflict.Seqno
+			if conflict.Table != "" {
+				out += "\n\t" + utils.Paint(utils.BlueText, "table: ") + conflict.Table
 			}
-			out = string(tmp)
-		} else if c.Json {
-			tmp, err := json.Marshal(ctx.Conflicts)
-			if err != nil {
-				return err
+			if !conflict.IsVoted() {
+				out += "\n\t" + utils.Paint(utils.BlueText, "failed to apply on:")
+				for node, vote := range conflict.VotePerNode {
+					out += "\n\t\t" + utils.Paint(utils.BlueText, node) + ": " + vote.Error
+					if vote.ErrorCode != "" {
+						out += " (" + utils.Paint(utils.RedText, "Error_code: "+vote.ErrorCode) + ")"
+					}
+				}
+				continue
 			}
-			out = string(tmp)
-		} else {
+			out += "\n\t" + utils.Paint(utils.BlueText, "winner: ") + conflict.Winner
+			out += "\n\t" + utils.Paint(utils.BlueText, "votes per nodes:")
+			for node, vote := range conflict.VotePerNode {
+				displayVote := utils.Paint(utils.RedText, vote.MD5)
+				if vote.MD5 == conflict.Winner {
+					displayVote = utils.Paint(utils.GreenText, vote.MD5)
+				}
+				out += "\n\t\t" + utils.Paint(utils.BlueText, node) + ": (" + displayVote + ") " + vote.Error
+			}
+			out += "\n\t" + utils.Paint(utils.BlueText, "initiated by: ") + fmt.Sprintf("%v", conflict.InitiatedBy)
+		}
 
-			for _, conflict := range ctx.Conflicts {
-				out += "\n"
-				out += "\n" + utils.Paint(utils.BlueText, "seqno: ") + conflict.Seqno
-				out += "\n\t" + utils.Paint(utils.BlueText, "winner: ") + conflict.Winner
-				out += "\n\t" + utils.Paint(utils.BlueText, "votes per nodes:")
-				for node, vote := range conflict.VotePerNode {
-					displayVote := utils.Paint(utils.RedText, vote.MD5)
-					if vote.MD5 == conflict.Winner {
-						displayVote = utils.Paint(utils.GreenText, vote.MD5)
+	}
+	fmt.Println(out)
+	return nil
+}
+
+// collectConflicts gets the votes from a single node: every Galera 4 node logs every vote
+// Galera 3 nodes only log their own applier errors, they are taken from every nodes
+func collectConflicts(ctxs map[string]types.LogCtx) types.Conflicts {
+	nodes := []string{}
+	for node := range ctxs {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	conflicts := types.Conflicts{}
+	votesFrom := ""
+	for _, node := range nodes {
+		for _, conflict := range ctxs[node].Conflicts {
+			// several nodes can fail to apply the same trx
+			if !conflict.IsVoted() {
+				if same := conflicts.ConflictWithSeqno(conflict.Seqno); same != nil && conflict.Seqno != "" && !same.IsVoted() {
+					for n, vote := range conflict.VotePerNode {
+						same.VotePerNode[n] = vote
 					}
-					out += "\n\t\t" + utils.Paint(utils.BlueText, node) + ": (" + displayVote + ") " + vote.Error
+					continue
 				}
-				out += "\n\t" + utils.Paint(utils.BlueText, "initiated by: ") + fmt.Sprintf("%v", conflict.InitiatedBy)
+				// votes of the next nodes are merged in it, the conflict of this node should not be modified
+				copied := *conflict
+				copied.VotePerNode = make(map[string]types.ConflictVote, len(conflict.VotePerNode))
+				for n, vote := range conflict.VotePerNode {
+					copied.VotePerNode[n] = vote
+				}
+				conflicts = append(conflicts, &copied)
+				continue
+			}
+			if votesFrom == "" {
+				votesFrom = node
+			}
+			if votesFrom == node {
+				conflicts = append(conflicts, conflict)
 			}
+		}
+	}
+	return conflicts
+}
 
+func (c *conflicts) printCertification(report types.CertificationReport) error {
+	if c.Yaml || c.Json {
+		var (
+			out []byte
+			err error
+		)
+		if c.Yaml {
+			out, err = yaml.Marshal(report)
+		} else {
+			out, err = json.Marshal(report)
 		}
-		fmt.Println(out)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	if len(report.PerNode) == 0 {
+		fmt.Println("no certification conflict found, they are only logged with wsrep_log_conflicts")
 		return nil
 	}
 
+	nodes := []string{}
+	for node := range report.PerNode {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	out := utils.Paint(utils.BlueText, "per node:")
+	for _, node := range nodes {
+		out += "\n\t" + node + ":"
+		for _, kind := range []types.CertificationConflictKind{types.CertificationFailure, types.BFAbort, types.MDLBFBFConflict, types.ReplayFailure} {
+			if count, ok := report.PerNode[node][kind]; ok {
+				out += " " + string(kind) + ": " + strconv.Itoa(count) + ","
+			}
+		}
+		out = out[:len(out)-1]
+	}
+
+	out += "\n" + utils.Paint(utils.BlueText, "losing writes by node:")
+	for _, loser := range report.Losers {
+		out += "\n\t" + loser.Key + ": " + strconv.Itoa(loser.Count)
+	}
+
+	out += "\n" + utils.Paint(utils.BlueText, "hot periods:")
+	for _, period := range report.HotPeriods {
+		out += "\n\t" + period.Key + ": " + strconv.Itoa(period.Count)
+	}
+	fmt.Println(out)
 	return nil
 }
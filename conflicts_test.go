@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+)
+
+func TestCollectConflictsApplierErrors(t *testing.T) {
+	node1 := &types.Conflict{Seqno: "12", Table: "test.t1", VotePerNode: map[string]types.ConflictVote{"node1": {Error: "Can't find record", ErrorCode: "1032"}}}
+	node2 := &types.Conflict{Seqno: "12", Table: "test.t1", VotePerNode: map[string]types.ConflictVote{"node2": {Error: "Can't find record", ErrorCode: "1032"}}}
+	ctxs := map[string]types.LogCtx{
+		"node1": {Conflicts: types.Conflicts{node1}},
+		"node2": {Conflicts: types.Conflicts{node2}},
+	}
+
+	conflicts := collectConflicts(ctxs)
+	if len(conflicts) != 1 || len(conflicts[0].VotePerNode) != 2 {
+		t.Fatalf("expected a single conflict with the votes of both nodes, got %v", conflicts)
+	}
+	if len(node1.VotePerNode) != 1 {
+		t.Errorf("the conflict of node1 was modified: %v", node1.VotePerNode)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/input"
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+type discover struct {
+	Paths    []string `arg:"" name:"paths" help:"paths of the log to use"`
+	Top      int      `default:"20" help:"Number of templates to show, 0 to show all of them"`
+	Skeleton int      `help:"Print a regex definition for the template of this rank, to complete and use with --regex-file"`
+	Json     bool
+}
+
+func (d *discover) Help() string {
+	return `Find errors, warnings and galera messages that no regex knows about.
+Values (numbers, UUIDs, IPs, hashes, paths) are replaced by placeholders so that similar messages are counted together`
+}
+
+// discoveredLine waits for the file to be fully read: the node it belongs to is only known at the end
+type discoveredLine struct {
+	template string
+	line     string
+	date     *types.Date
+}
+
+func (d *discover) Run() error {
+	if d.Skeleton < 0 {
+		return errors.New("--skeleton is a rank, starting from 1")
+	}
+
+	regexes := nativeRegexes(regex.AllRegexes())
+	filter := prepareNativeFilter(regexes)
+
+	paths, err := input.ExpandPaths(d.Paths)
+	if err != nil {
+		return err
+	}
+	templates := types.DiscoveredTemplates{}
+	for _, path := range paths {
+		if err := discoverFile(path, regexes, filter, templates); err != nil {
+			return err
+		}
+	}
+
+	top := templates.Top(d.Top)
+	if d.Skeleton > 0 {
+		if d.Skeleton > len(top) {
+			return errors.Errorf("there are only %d templates", len(top))
+		}
+		skeleton := regex.SkeletonRegex("RegexDiscovered"+strconv.Itoa(d.Skeleton), top[d.Skeleton-1].Template)
+		out, err := regex.MarshalRegexFile([]regex.CustomRegex{skeleton})
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(out))
+		return nil
+	}
+
+	if d.Json {
+		out, err := json.Marshal(top)
+		if err != nil {
+			return errors.Wrap(err, "could not marshal templates")
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	for i, t := range top {
+		out := fmt.Sprintf("%d. %s occurrences", i+1, utils.Paint(utils.BlueText, strconv.Itoa(t.Count)))
+		if t.First != nil && t.Last != nil {
+			out += ", from " + t.First.DisplayTime + " to " + t.Last.DisplayTime
+		}
+		out += "\n\t" + t.Template
+		out += "\n\t" + utils.Paint(utils.BlueText, "per node: ") + formatPerNode(t.PerNode)
+		out += "\n\t" + utils.Paint(utils.BlueText, "example: ") + t.Example
+		fmt.Println(out)
+	}
+	return nil
+}
+
+// discoverFile collects the lines no regex would find
+// Lines the regexes find are still handled, only to identify the node
+func discoverFile(path string, regexes types.RegexMap, filter *nativeFilter, templates types.DiscoveredTemplates) error {
+	f, err := input.OpenFormat(path, CLI.InputFormat)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", path)
+	}
+	defer f.Close()
+
+	lineRegexes, recordRegexes := regexes.SplitMultiLine()
+	p := newLineProcessor(path, regexes)
+	lt := types.LocalTimeline{}
+	assembler := &recordAssembler{filter: filter}
+	discovered := []discoveredLine{}
+
+	// lines of a record are only known once the record is complete
+	pending := []discoveredLine{}
+	recordKnown := false
+	process := func(result searchResult) {
+		if result.record && knownBy(recordRegexes, result.text) {
+			recordKnown = true
+		}
+		lt, _ = p.processResult(lt, result)
+	}
+	endRecord := func() {
+		if !recordKnown {
+			discovered = append(discovered, pending...)
+		}
+		pending = pending[:0]
+		recordKnown = false
+	}
+
+	s := bufio.NewScanner(f)
+	s.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+	for s.Scan() {
+		line := s.Text()
+		if assembler.feed(line, process) {
+			endRecord()
+		}
+
+		if !regex.IsDiscoverable(line) || knownBy(lineRegexes, line) {
+			continue
+		}
+		date := regex.DateFromLog(p.ctx, line)
+		if date != nil && ((CLI.Since != nil && CLI.Since.After(date.Time)) || (CLI.Until != nil && CLI.Until.Before(date.Time))) {
+			continue
+		}
+		pending = append(pending, discoveredLine{template: regex.LogTemplate(line), line: line, date: date})
+	}
+	assembler.flush(process)
+	endRecord()
+	if err := s.Err(); err != nil {
+		return errors.Wrapf(err, "failed to read %s", path)
+	}
+
+	node := p.node()
+	for _, d := range discovered {
+		templates.Add(d.template, d.line, node, d.date)
+	}
+	return nil
+}
+
+// knownBy tells if a regex would handle the text, the same way lineProcessor does
+// matching the prefilter is not enough: handlers are not called when InternalRegex does not match
+func knownBy(regexes types.RegexMap, text string) bool {
+	text = regex.StripSyslogPrefix(text)
+	for key, r := range regexes {
+		if utils.SliceContains(CLI.ExcludeRegexes, key) || !r.Regex.MatchString(text) {
+			continue
+		}
+		if _, ok := r.Submatches(text); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func formatPerNode(perNode map[string]int) string {
+	nodes := []string{}
+	for node := range perNode {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	out := ""
+	for i, node := range nodes {
+		if i > 0 {
+			out += ", "
+		}
+		out += fmt.Sprintf("%s: %d", node, perNode[node])
+	}
+	return out
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
+)
+
+func TestDiscoverFile(t *testing.T) {
+	defer func(excluded []string) { CLI.ExcludeRegexes = excluded }(CLI.ExcludeRegexes)
+	CLI.ExcludeRegexes = []string{"RegexFlowControlStop"}
+
+	path := filepath.Join(t.TempDir(), "mysqld.log")
+	err := os.WriteFile(path, []byte(
+		// known
+		"2023-01-01T01:00:00.000000Z 0 [Note] [MY-000000] [Galera] Flow-control interval: [100, 100]\n"+
+			// the prefilter matches, but not InternalRegex
+			"2023-01-01T01:00:01.000000Z 0 [Note] [MY-000000] [Galera] Flow-control interval: unknown\n"+
+			// excluded
+			"2023-01-01T01:00:02.000000Z 0 [Note] [MY-000000] [Galera] SENDING FC_STOP (local seqno: 1234, fc_offset: 0, cond: 1)\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	regexes := nativeRegexes(types.RegexMap{}.Merge(regex.ApplicativeMap))
+	templates := types.DiscoveredTemplates{}
+	if err := discoverFile(path, regexes, prepareNativeFilter(regexes), templates); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"<num> [Note] [MY-<num>] [Galera] Flow-control interval: unknown",
+		"<num> [Note] [MY-<num>] [Galera] SENDING FC_STOP (local seqno: <num>, fc_offset: <num>, cond: <num>)",
+	}
+	if len(templates) != len(expected) {
+		t.Fatalf("expected %d templates, got %v", len(expected), templates.Top(0))
+	}
+	for _, template := range expected {
+		if _, ok := templates[template]; !ok {
+			t.Errorf("missing template %q, got %v", template, templates.Top(0))
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package display
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+// FlowControlSummary prints the periods where writes were throttled, and by which node
+// then the flow control messages each node ignored while it was non-primary
+func FlowControlSummary(ctxs map[string]types.LogCtx) {
+	pauses := types.FlowControlPauses(ctxs)
+	if len(pauses) > 0 {
+		fmt.Println("\n" + utils.Paint(utils.YellowText, "writes paused by flow control:"))
+	}
+	for _, pause := range pauses {
+		out := "\t" + pause.Start.DisplayTime + " -> "
+		if d, ok := pause.Duration(); ok {
+			out += pause.End.DisplayTime + " (" + d.Round(time.Millisecond).String() + ")"
+		} else {
+			out += "end of log"
+		}
+		fmt.Println(out + ", caused by " + pause.Node)
+	}
+
+	nodes := []string{}
+	for node, ctx := range ctxs {
+		if ctx.FlowControl.Ignored > 0 {
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) == 0 {
+		return
+	}
+	sort.Strings(nodes)
+	fmt.Println("\n" + utils.Paint(utils.YellowText, "flow control messages ignored while non-primary:"))
+	for _, node := range nodes {
+		fmt.Println("\t" + node + ": " + strconv.Itoa(ctxs[node].FlowControl.Ignored))
+	}
+}
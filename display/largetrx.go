@@ -0,0 +1,55 @@
+package display
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+var largeTransactionKinds = []types.LargeTransactionKind{types.WriteSetTooLarge, types.SRFragmentApplyFailure}
+
+// LargeTransactionsSummary prints, per node, how many transactions were too large to replicate
+// The last one gives the connection to look for, usually a batch job
+func LargeTransactionsSummary(ctxs map[string]types.LogCtx) {
+	nodes := []string{}
+	for node, ctx := range ctxs {
+		if len(ctx.LargeTransactions.Counts) > 0 {
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) == 0 {
+		return
+	}
+	sort.Strings(nodes)
+
+	fmt.Println("\n" + utils.Paint(utils.YellowText, "large transactions:"))
+	for _, node := range nodes {
+		lt := ctxs[node].LargeTransactions
+		out := "\t" + node + ":"
+		for _, kind := range largeTransactionKinds {
+			if count := lt.Counts[kind]; count > 0 {
+				out += " " + strconv.Itoa(count) + " " + string(kind) + ","
+			}
+		}
+		if lt.Largest > 0 {
+			out += " largest " + utils.HumanBytes(lt.Largest) + ","
+		}
+		if lt.Last != nil {
+			out += " last"
+			if lt.Last.Date != nil {
+				out += " at " + lt.Last.Date.DisplayTime
+			}
+			if lt.Last.ConnID != "" {
+				out += " from conn_id:" + lt.Last.ConnID
+			}
+			if lt.Last.TrxID != "" {
+				out += " trx_id:" + lt.Last.TrxID
+			}
+		}
+		fmt.Println(strings.TrimSuffix(out, ","))
+	}
+}
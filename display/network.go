@@ -0,0 +1,61 @@
+package display
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+// NetworkIssuesSummary prints, per node, the network issues it had with each peer
+// Misconfigurations come first: they will not fix themselves
+func NetworkIssuesSummary(ctxs map[string]types.LogCtx) {
+	nodes := []string{}
+	for node, ctx := range ctxs {
+		if len(ctx.NetworkIssues) > 0 {
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) == 0 {
+		return
+	}
+	sort.Strings(nodes)
+
+	fmt.Println("\n" + utils.Paint(utils.YellowText, "network issues:"))
+	for _, node := range nodes {
+		ctx := ctxs[node]
+		fmt.Println("\t" + node + ":")
+		for _, kind := range []types.NetworkIssueKind{types.NetworkMisconfiguration, types.NetworkTransient} {
+			for _, issue := range sortedNetworkIssues(ctx, kind) {
+				peer := "unknown peer"
+				if issue.Peer != "" {
+					peer = types.DisplayPeerSimplestForm(ctx, issue.Peer)
+				}
+				out := "\t\t" + peer + ": " + strconv.Itoa(issue.Count) + " " + string(kind) + ", last"
+				if issue.Last != nil {
+					out += " at " + issue.Last.DisplayTime
+				}
+				out += ": " + issue.Reason
+				if kind == types.NetworkMisconfiguration {
+					out = utils.Paint(utils.RedText, out)
+				}
+				fmt.Println(out)
+			}
+		}
+	}
+}
+
+func sortedNetworkIssues(ctx types.LogCtx, kind types.NetworkIssueKind) []types.NetworkIssue {
+	issues := []types.NetworkIssue{}
+	for _, issue := range ctx.NetworkIssues {
+		if issue.Kind == kind {
+			issues = append(issues, issue)
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool {
+		return issues[i].Peer < issues[j].Peer
+	})
+	return issues
+}
@@ -2,6 +2,7 @@ package display
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"sort"
@@ -35,8 +36,41 @@ func TimelineCLI(timeline types.Timeline, verbosity types.Verbosity) {
 	fmt.Fprintln(w, headerIP(keys, latestContext))
 	fmt.Fprintln(w, headerName(keys, latestContext))
 	fmt.Fprintln(w, headerVersion(keys, latestContext))
+	if hasClockSkew(latestContext) {
+		fmt.Fprintln(w, headerClockSkew(keys, latestContext))
+	}
+	if hasResourceExhaustion(latestContext) {
+		fmt.Fprintln(w, headerResourceExhaustion(keys, latestContext))
+	}
 	fmt.Fprintln(w, separator(keys))
 
+	p := &timelinePrinter{keys: keys, currentContext: currentContext, lastContext: lastContext, verbosity: verbosity}
+	linecount := p.printEvents(w, timeline, latestContext)
+
+	// footer
+	// only having a header is not fast enough to read when there are too many lines
+	if linecount >= 50 {
+		fmt.Fprintln(w, separator(keys))
+		fmt.Fprintln(w, headerNodes(keys))
+		fmt.Fprintln(w, headerFilePath(keys, currentContext))
+		fmt.Fprintln(w, headerIP(keys, currentContext))
+		fmt.Fprintln(w, headerName(keys, currentContext))
+		fmt.Fprintln(w, headerVersion(keys, currentContext))
+	}
+
+	// TODO: where to print conflicts details ?
+}
+
+// timelinePrinter holds what is needed to print rows, so that they can be printed in several batches
+type timelinePrinter struct {
+	keys           []string
+	currentContext map[string]types.LogCtx // currentcontext to follow when important thing changed
+	lastContext    map[string]types.LogCtx // just to follow when important thing changed
+	verbosity      types.Verbosity
+}
+
+// printEvents dequeues the timeline chronologically, and returns the number of lines printed
+func (p *timelinePrinter) printEvents(w io.Writer, timeline types.Timeline, latestContext map[string]types.LogCtx) int {
 	var (
 		args      []string // stuff to print
 		linecount int
@@ -55,23 +89,23 @@ func TimelineCLI(timeline types.Timeline, verbosity types.Verbosity) {
 		displayedValue := 0
 
 		// node values
-		for _, node := range keys {
+		for _, node := range p.keys {
 
 			if !utils.SliceContains(nextNodes, node) {
 				// if there are no events, having a | is needed for tabwriter
 				// A few color can also help highlighting how the node is doing
-				ctx := currentContext[node]
+				ctx := p.currentContext[node]
 				args = append(args, utils.PaintForState("| ", ctx.State()))
 				continue
 			}
 			loginfo := timeline[node][0]
-			lastContext[node] = currentContext[node]
-			currentContext[node] = loginfo.Ctx
+			p.lastContext[node] = p.currentContext[node]
+			p.currentContext[node] = loginfo.Ctx
 
 			timeline.Dequeue(node)
 
 			msg := loginfo.Msg(latestContext[node])
-			if verbosity > loginfo.Verbosity && msg != "" {
+			if p.verbosity > loginfo.Verbosity && msg != "" {
 				args = append(args, msg)
 				displayedValue++
 			} else {
@@ -79,12 +113,12 @@ func TimelineCLI(timeline types.Timeline, verbosity types.Verbosity) {
 			}
 		}
 
-		if sep := transitionSeparator(keys, lastContext, currentContext); sep != "" {
+		if sep := transitionSeparator(p.keys, p.lastContext, p.currentContext); sep != "" {
 			// reset current context, so that we avoid duplicating transitions
 			// lastContext/currentContext is only useful for that anyway
-			lastContext = map[string]types.LogCtx{}
-			for k, v := range currentContext {
-				lastContext[k] = v
+			p.lastContext = map[string]types.LogCtx{}
+			for k, v := range p.currentContext {
+				p.lastContext[k] = v
 			}
 			// print transition
 			fmt.Fprintln(w, sep)
@@ -103,19 +137,7 @@ func TimelineCLI(timeline types.Timeline, verbosity types.Verbosity) {
 		}
 		linecount++
 	}
-
-	// footer
-	// only having a header is not fast enough to read when there are too many lines
-	if linecount >= 50 {
-		fmt.Fprintln(w, separator(keys))
-		fmt.Fprintln(w, headerNodes(keys))
-		fmt.Fprintln(w, headerFilePath(keys, currentContext))
-		fmt.Fprintln(w, headerIP(keys, currentContext))
-		fmt.Fprintln(w, headerName(keys, currentContext))
-		fmt.Fprintln(w, headerVersion(keys, currentContext))
-	}
-
-	// TODO: where to print conflicts details ?
+	return linecount
 }
 
 func initKeysContext(timeline types.Timeline) ([]string, map[string]types.LogCtx) {
@@ -195,6 +217,49 @@ func headerName(keys []string, ctxs map[string]types.LogCtx) string {
 	return header
 }
 
+func hasClockSkew(ctxs map[string]types.LogCtx) bool {
+	for _, ctx := range ctxs {
+		if ctx.ClockSkew != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func headerClockSkew(keys []string, ctxs map[string]types.LogCtx) string {
+	header := "clock corrected by\t"
+	for _, node := range keys {
+		if ctx, ok := ctxs[node]; ok && ctx.ClockSkew != 0 {
+			header += types.SignedDuration(-ctx.ClockSkew) + "\t"
+		} else {
+			header += " \t"
+		}
+	}
+	return header
+}
+
+func hasResourceExhaustion(ctxs map[string]types.LogCtx) bool {
+	for _, ctx := range ctxs {
+		if ctx.ResourceExhaustion != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// the node is still starved at the end of its log: it did not restart since
+func headerResourceExhaustion(keys []string, ctxs map[string]types.LogCtx) string {
+	header := "ran out of\t"
+	for _, node := range keys {
+		if ctx, ok := ctxs[node]; ok && ctx.ResourceExhaustion != nil {
+			header += utils.Paint(utils.RedText, ctx.ResourceExhaustion.Resource) + "\t"
+		} else {
+			header += " \t"
+		}
+	}
+	return header
+}
+
 func removeEmptyColumns(timeline types.Timeline, verbosity types.Verbosity) types.Timeline {
 
 	for key := range timeline {
@@ -0,0 +1,85 @@
+package display
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/Ladicle/tabwriter"
+	"github.com/ylacancellera/galera-log-explainer/types"
+)
+
+// tabwriter can only align rows that are flushed together
+// each batch is printed separately, so a minimal width keeps columns mostly aligned
+const followColumnWidth = 40
+
+// TimelineFollow prints a timeline progressively, batch after batch, for live outputs
+type TimelineFollow struct {
+	printer *timelinePrinter
+	latest  map[string]types.LogCtx // latest context of each column, to translate messages with the most updated information
+}
+
+func NewTimelineFollow(verbosity types.Verbosity) *TimelineFollow {
+	return &TimelineFollow{
+		printer: &timelinePrinter{
+			currentContext: map[string]types.LogCtx{},
+			lastContext:    map[string]types.LogCtx{},
+			verbosity:      verbosity,
+		},
+		latest: map[string]types.LogCtx{},
+	}
+}
+
+// Print dequeues and prints every events of the timeline
+// A node seen for the first time gets a new column, and headers are printed again
+func (f *TimelineFollow) Print(timeline types.Timeline) {
+
+	newColumns := false
+	for node, lt := range timeline {
+		if len(lt) == 0 {
+			delete(timeline, node)
+			continue
+		}
+		latest := lt[len(lt)-1].Ctx
+		f.latest[node] = latest
+
+		if _, ok := f.printer.currentContext[node]; ok {
+			continue
+		}
+		// same as removeEmptyColumns: the node will get its column once it has something to show
+		if !latest.HasVisibleEvents(f.printer.verbosity) {
+			delete(timeline, node)
+			continue
+		}
+		f.printer.keys = append(f.printer.keys, node)
+		f.printer.currentContext[node] = lt[0].Ctx
+		newColumns = true
+	}
+	sort.Strings(f.printer.keys)
+
+	latestContext := map[string]types.LogCtx{}
+	latestctxs := []types.LogCtx{}
+	for node, ctx := range f.latest {
+		latestContext[node] = ctx
+		latestctxs = append(latestctxs, ctx)
+	}
+	for _, ctx := range latestContext {
+		ctx.MergeMapsWith(latestctxs)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, followColumnWidth, 8, 3, ' ', tabwriter.DiscardEmptyColumns)
+	defer w.Flush()
+
+	if newColumns {
+		keys := f.printer.keys
+		fmt.Fprintln(w, separator(keys))
+		fmt.Fprintln(w, headerNodes(keys))
+		fmt.Fprintln(w, headerFilePath(keys, f.printer.currentContext))
+		fmt.Fprintln(w, headerIP(keys, latestContext))
+		fmt.Fprintln(w, headerName(keys, latestContext))
+		fmt.Fprintln(w, headerVersion(keys, latestContext))
+		fmt.Fprintln(w, separator(keys))
+	}
+
+	f.printer.printEvents(w, timeline, latestContext)
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+type explainLine struct {
+	Line string `arg:"" optional:"" name:"line" help:"the log line to explain. Lines are read from stdin when it is missing"`
+	Ctx  string `type:"existingfile" help:"context dumped by the 'ctx' subcommand, so that IPs and hashes are translated like in the rest of its log"`
+	Json bool
+}
+
+func (e *explainLine) Help() string {
+	return `Explain how a single log line is understood: which regexes match, what they extract, and what they change in the context.
+Lines read from stdin are explained one after the other, with the context built by the previous ones.
+They are also grouped in records like in log files, a dated line and the following lines without date: multi-line regexes are only explained on whole records`
+}
+
+type lineExplanation struct {
+	Line       string             `json:"line"`
+	Date       *types.Date        `json:"date,omitempty"`
+	DateLayout string             `json:"dateLayout,omitempty"` // as found in the log, before the timezone and year were completed
+	Matches    []regexExplanation `json:"matches"`
+	ctx        types.LogCtx
+}
+
+type regexExplanation struct {
+	Key             string            `json:"key"`
+	Type            types.RegexType   `json:"type"`
+	Verbosity       types.Verbosity   `json:"verbosity"`
+	InternalMatched bool              `json:"internalMatched"`
+	Terminal        bool              `json:"terminal,omitempty"`
+	Excluded        bool              `json:"excluded,omitempty"` // by --exclude-regexes, the handler was not called
+	Submatches      map[string]string `json:"submatches,omitempty"`
+	Changes         []types.CtxChange `json:"changes,omitempty"`
+	Message         string            `json:"message,omitempty"`
+}
+
+func (e *explainLine) Run() error {
+	ctx := types.NewLogCtx()
+	if e.Ctx != "" {
+		var err error
+		ctx, err = loadCtx(e.Ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	lines := []string{e.Line}
+	if e.Line == "" {
+		lines = []string{}
+		s := bufio.NewScanner(os.Stdin)
+		s.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+		for s.Scan() {
+			if strings.TrimSpace(s.Text()) != "" {
+				lines = append(lines, s.Text())
+			}
+		}
+		if err := s.Err(); err != nil {
+			return errors.Wrap(err, "failed to read stdin")
+		}
+	}
+	if len(lines) == 0 {
+		return errors.New("no line to explain")
+	}
+
+	regexes := regex.AllRegexes()
+	if CLI.PxcOperator {
+		regexes.Merge(regex.PXCOperatorMap)
+	}
+	// same as the search engines: lines regexes superseded by MultiLine ones are not used by the native engine
+	if CLI.Engine != "grep" {
+		regexes = nativeRegexes(regexes)
+	}
+
+	explanations, err := explainLines(ctx, regexes, lines)
+	if err != nil {
+		return err
+	}
+
+	if e.Json {
+		out, err := json.Marshal(explanations)
+		if err != nil {
+			return errors.Wrap(err, "could not marshal explanations")
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	for _, explanation := range explanations {
+		fmt.Println(explanation.String())
+	}
+	return nil
+}
+
+func loadCtx(path string) (types.LogCtx, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return types.LogCtx{}, errors.Wrap(err, "failed to open context")
+	}
+	defer f.Close()
+
+	// the ctx subcommand prints one context per node, only the first one is used
+	ctx := types.LogCtx{}
+	if err := json.NewDecoder(f).Decode(&ctx); err != nil {
+		return types.LogCtx{}, errors.Wrapf(err, "invalid context %s", path)
+	}
+	return ctx, nil
+}
+
+// explainLines explains each line with line regexes, and each record with MultiLine regexes, the same way files are searched
+// a record is explained when the next one starts, only when a MultiLine regex matched it
+func explainLines(ctx types.LogCtx, regexes types.RegexMap, lines []string) ([]lineExplanation, error) {
+	lineRegexes, recordRegexes := regexes.SplitMultiLine()
+	explanations := []lineExplanation{}
+	record := []string{}
+
+	explainRecord := func() error {
+		if len(record) == 0 || len(recordRegexes) == 0 {
+			return nil
+		}
+		explanation, err := explain(ctx, recordRegexes, strings.Join(record, "\n"))
+		record = record[:0]
+		if err != nil || len(explanation.Matches) == 0 {
+			return err
+		}
+		ctx = explanation.ctx
+		explanations = append(explanations, explanation)
+		return nil
+	}
+
+	for _, line := range lines {
+		line = sanitizeLine(line)
+		content := line
+		if message, ok := regex.SyslogMessage(line); ok {
+			content = message
+		}
+		if _, _, ok := regex.SearchDateFromLog(content); ok {
+			if err := explainRecord(); err != nil {
+				return nil, err
+			}
+		}
+		record = append(record, line)
+
+		explanation, err := explain(ctx, lineRegexes, line)
+		if err != nil {
+			return nil, err
+		}
+		ctx = explanation.ctx
+		explanations = append(explanations, explanation)
+	}
+	if err := explainRecord(); err != nil {
+		return nil, err
+	}
+	return explanations, nil
+}
+
+// explain handles the line the same way lineProcessor.handle does, keeping track of every step
+func explain(ctx types.LogCtx, regexes types.RegexMap, line string) (lineExplanation, error) {
+	explanation := lineExplanation{Line: line, Matches: []regexExplanation{}}
+
+	if _, layout, ok := regex.SearchDateFromLog(line); ok {
+		explanation.DateLayout = layout
+		explanation.Date = regex.DateFromLog(ctx, line)
+	}
+
+	if host, ok := regex.SyslogHost(line); ok {
+		ctx.SetHostname(host)
+	}
+	line = regex.StripSyslogPrefix(line)
+	ctx.FileType = regex.FileType(line, CLI.PxcOperator)
+
+	for _, key := range regexes.OrderedKeys() {
+		logRegex := regexes[key]
+		if !logRegex.Regex.MatchString(line) {
+			continue
+		}
+		if utils.SliceContains(CLI.ExcludeRegexes, key) {
+			explanation.Matches = append(explanation.Matches, regexExplanation{Key: key, Type: logRegex.Type, Verbosity: logRegex.Verbosity, Excluded: true})
+			continue
+		}
+		submatches, internalMatched := logRegex.Submatches(line)
+		// handlers modify maps in place, the context has to be copied to be compared
+		before := types.LogCtx{}
+		raw, err := json.Marshal(ctx)
+		if err != nil {
+			return explanation, errors.Wrap(err, "failed to copy context")
+		}
+		if err := json.Unmarshal(raw, &before); err != nil {
+			return explanation, errors.Wrap(err, "failed to copy context")
+		}
+
+		var displayer types.LogDisplayer
+		ctx, displayer = logRegex.Handle(ctx, line)
+		changes, err := types.DiffCtx(before, ctx)
+		if err != nil {
+			return explanation, errors.Wrap(err, "failed to compare contexts")
+		}
+
+		match := regexExplanation{
+			Key:             key,
+			Type:            logRegex.Type,
+			Verbosity:       logRegex.Verbosity,
+			InternalMatched: internalMatched,
+			Terminal:        logRegex.Terminal,
+			Submatches:      submatches,
+			Changes:         changes,
+		}
+		if displayer != nil {
+			match.Message = displayer(ctx)
+		}
+		explanation.Matches = append(explanation.Matches, match)
+		if logRegex.Terminates(line) {
+			break
+		}
+	}
+	explanation.ctx = ctx
+	return explanation, nil
+}
+
+func (e lineExplanation) String() string {
+	out := utils.Paint(utils.BlueText, "line: ") + e.Line
+	if e.Date != nil {
+		out += "\n" + utils.Paint(utils.BlueText, "date: ") + e.Date.Time.String() + " (layout \"" + e.DateLayout + "\")"
+	} else {
+		out += "\n" + utils.Paint(utils.BlueText, "date: ") + "not found"
+	}
+	if len(e.Matches) == 0 {
+		out += "\n" + utils.Paint(utils.YellowText, "no regex matched")
+	}
+
+	for _, match := range e.Matches {
+		out += "\n" + utils.Paint(utils.GreenText, match.Key) + fmt.Sprintf(" (%s, verbosity %d)", match.Type, match.Verbosity)
+		if match.Excluded {
+			out += "\n\t" + utils.Paint(utils.YellowText, "excluded by --exclude-regexes, the handler was not called")
+			continue
+		}
+		if match.Terminal && match.InternalMatched {
+			out += ", terminal: the next regexes were not applied"
+		}
+		if !match.InternalMatched {
+			out += "\n\t" + utils.Paint(utils.RedText, "internal regex did not match, the handler was not called")
+			continue
+		}
+		if len(match.Submatches) > 0 {
+			names := []string{}
+			for name := range match.Submatches {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			out += "\n\t" + utils.Paint(utils.BlueText, "submatches:")
+			for _, name := range names {
+				out += "\n\t\t" + name + ": " + match.Submatches[name]
+			}
+		}
+		if len(match.Changes) > 0 {
+			out += "\n\t" + utils.Paint(utils.BlueText, "context changes:")
+			for _, change := range match.Changes {
+				out += fmt.Sprintf("\n\t\t%s: %v -> %v", change.Field, change.Before, change.After)
+			}
+		}
+		if match.Message != "" {
+			out += "\n\t" + utils.Paint(utils.BlueText, "message: ") + match.Message
+		} else {
+			out += "\n\t" + utils.Paint(utils.BlueText, "message: ") + "nothing is displayed"
+		}
+	}
+	return out
+}
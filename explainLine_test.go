@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
+)
+
+func TestExplainLines(t *testing.T) {
+	defer func(excluded []string) { CLI.ExcludeRegexes = excluded }(CLI.ExcludeRegexes)
+	CLI.ExcludeRegexes = []string{"RegexFlowControlStop"}
+
+	lines := []string{
+		"2023-05-28T21:18:23.184707-05:00 0 [Note] [MY-000000] [Galera] ================================================",
+		"View:",
+		"  id: 9f191762-2542-11ee-89be-13bdb1218f0e:9339113",
+		"  status: primary",
+		"  protocol_version: 4",
+		"  final: no",
+		"  own_index: 1",
+		"  members(2):",
+		"\t0: 015702fc-32f5-11ed-a4ca-267f97316394, node1",
+		"\t1: 08dd5580-32f7-11ed-a9eb-af5e3d01519e, node2",
+		"=================================================",
+		"2023-05-28T21:18:23.195820-05:00 0 [Note] [MY-000000] [Galera] SENDING FC_STOP (local seqno: 1234, fc_offset: 0, cond: 1)",
+	}
+	regexes := nativeRegexes(regex.AllRegexes())
+	explanations, err := explainLines(types.NewLogCtx(), regexes, lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every line, then the record before the next dated line
+	if len(explanations) != len(lines)+1 {
+		t.Fatalf("expected %d explanations, got %d", len(lines)+1, len(explanations))
+	}
+	record := explanations[len(lines)-1]
+	if len(record.Matches) != 1 || record.Matches[0].Key != "RegexOwnNameFromView" || !record.Matches[0].InternalMatched {
+		t.Errorf("expected the view record to be explained by RegexOwnNameFromView, got %+v", record.Matches)
+	}
+	if record.ctx.HashToNodeName["015702fc-a4ca"] != "node1" {
+		t.Errorf("expected the context to be updated by the record, got %v", record.ctx.HashToNodeName)
+	}
+
+	last := explanations[len(explanations)-1]
+	if len(last.Matches) != 1 || !last.Matches[0].Excluded || last.Matches[0].Message != "" {
+		t.Errorf("expected RegexFlowControlStop to be excluded, got %+v", last.Matches)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/display"
+	"github.com/ylacancellera/galera-log-explainer/input"
+	"github.com/ylacancellera/galera-log-explainer/types"
+)
+
+const followPollInterval = time.Second
+
+// followedFile is a log being tailed
+// Its lineProcessor, and so its context, is kept alive across rotations
+type followedFile struct {
+	path      string
+	column    string
+	proc      *lineProcessor
+	assembler *recordAssembler
+	file      *os.File
+	info      os.FileInfo
+	reader    *bufio.Reader
+	offset    int64
+	partial   string // last line, not yet terminated by a newline
+	journald  bool
+}
+
+// followPaths behaves like "tail -F": it prints the current timeline, then keep printing new events as they come
+func followPaths(paths []string, regexes types.RegexMap, verbosity types.Verbosity) error {
+	regexes = nativeRegexes(regexes)
+	filter := prepareNativeFilter(regexes)
+
+	files := []*followedFile{}
+	for _, path := range paths {
+		if !input.IsPlainFile(path) {
+			return errors.Errorf("can't follow %s: compressed files and archives are not supported", path)
+		}
+		f := &followedFile{
+			path:      path,
+			proc:      newLineProcessor(path, regexes),
+			assembler: &recordAssembler{filter: filter},
+			journald:  input.IsJournaldJSON(path, CLI.InputFormat),
+		}
+		if err := f.open(); err != nil {
+			return err
+		}
+		files = append(files, f)
+	}
+
+	printer := display.NewTimelineFollow(verbosity)
+
+	// first batch: the existing content, merged the usual way
+	timeline := types.Timeline{}
+	for _, f := range files {
+		lt := f.readAvailable()
+		if len(lt) == 0 {
+			continue
+		}
+		f.column = columnFor(f.path, lt)
+		mergeLocalTimeline(timeline, f.path, lt)
+	}
+	printer.Print(timeline)
+
+	for {
+		time.Sleep(followPollInterval)
+		printer.Print(followBatch(files))
+	}
+}
+
+// followBatch gets what was written in every files since the last poll
+// files of the same node are merged like in the first batch, so that events stay ordered by date
+func followBatch(files []*followedFile) types.Timeline {
+	batch := types.Timeline{}
+	for _, f := range files {
+		lt := f.readAvailable()
+		rotated, err := f.handleRotation()
+		if err != nil {
+			logger.Warn().Str("path", f.path).Err(err).Msg("failed to follow rotation")
+		}
+		lt = append(lt, rotated...)
+		lt = append(lt, f.readAvailable()...)
+		if len(lt) == 0 {
+			continue
+		}
+
+		// columns should be stable: once a file is displayed somewhere, it stays there
+		if f.column == "" {
+			f.column = columnFor(f.path, lt)
+		}
+		batch[f.column] = types.MergeTimeline(batch[f.column], lt)
+	}
+	return batch
+}
+
+// columnFor mirrors mergeLocalTimeline to find where events of a file should go
+func columnFor(path string, lt types.LocalTimeline) string {
+	switch {
+	case CLI.PxcOperator:
+		return path
+	case CLI.MergeByDirectory:
+		return types.DirectoryIdentifier(path)
+	default:
+		return types.Identifier(lt[len(lt)-1].Ctx)
+	}
+}
+
+func (f *followedFile) open() error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", f.path)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return errors.Wrapf(err, "failed to stat %s", f.path)
+	}
+	f.file = file
+	f.info = info
+	f.reader = bufio.NewReader(file)
+	f.offset = 0
+	f.partial = ""
+	return nil
+}
+
+// readAvailable processes every complete lines written since the last call
+// a multi-line record is only complete once the next dated line is written
+func (f *followedFile) readAvailable() types.LocalTimeline {
+	var lt types.LocalTimeline
+
+	// the file is still being written, its modification time would be outdated after a few polls
+	if CLI.SyslogYear == 0 {
+		f.proc.ctx.YearReference = time.Now()
+	}
+	for {
+		chunk, err := f.reader.ReadString('\n')
+		f.offset += int64(len(chunk))
+		if err != nil {
+			// incomplete line: it is still being written
+			f.partial += chunk
+			if err != io.EOF {
+				logger.Warn().Str("path", f.path).Err(err).Msg("failed to read")
+			}
+			return lt
+		}
+		line := f.partial + chunk[:len(chunk)-1]
+		f.partial = ""
+		lt = f.processLine(lt, line)
+	}
+}
+
+func (f *followedFile) processLine(lt types.LocalTimeline, line string) types.LocalTimeline {
+	lines := []string{line}
+	if f.journald {
+		var err error
+		lines, err = input.JournaldEntryToLines([]byte(line))
+		if err != nil {
+			return lt
+		}
+	}
+	for _, line := range lines {
+		f.assembler.feed(line, func(result searchResult) {
+			lt, _ = f.proc.processResult(lt, result)
+		})
+	}
+	return lt
+}
+
+// flushPartial processes the last line as it is: the file will not be written anymore, it will never be terminated
+func (f *followedFile) flushPartial() types.LocalTimeline {
+	if f.partial == "" {
+		return nil
+	}
+	line := f.partial
+	f.partial = ""
+	return f.processLine(nil, line)
+}
+
+// handleRotation reopens the path when the file was renamed and recreated, or truncated
+// The previous file should have been completely read before calling it, its unterminated last line is given back
+func (f *followedFile) handleRotation() (types.LocalTimeline, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		// renamed, but not yet recreated: we will retry on the next poll
+		return nil, nil
+	}
+	if os.SameFile(info, f.info) {
+		if info.Size() < f.offset {
+			logger.Debug().Str("path", f.path).Msg("file truncated, reading from the start")
+			lt := f.flushPartial()
+			if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+				return lt, err
+			}
+			f.reader.Reset(f.file)
+			f.offset = 0
+			return lt, nil
+		}
+		return nil, nil
+	}
+
+	logger.Debug().Str("path", f.path).Msg("file rotated, reading the new one")
+	lt := f.flushPartial()
+	f.file.Close()
+	return lt, f.open()
+}
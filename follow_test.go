@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ylacancellera/galera-log-explainer/regex"
+)
+
+func TestFollowRotationKeepsPartialLine(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mysqld.log")
+	// the last line of the rotated file is not terminated by a newline
+	err := os.WriteFile(path, []byte("2001-01-01T01:01:01.000000Z 0 [Note] WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)\n"+
+		"2001-01-01T01:01:02.000000Z 0 [Note] WSREP: Shifting DONOR/DESYNCED -> JOINED (TO: 20)"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := openTestFollowedFile(t, path)
+	defer func() { f.file.Close() }()
+
+	if lt := f.readAvailable(); len(lt) != 1 {
+		t.Fatalf("expected only the terminated line, got %d events", len(lt))
+	}
+
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("2001-01-01T01:01:03.000000Z 0 [Note] WSREP: Shifting JOINED -> SYNCED (TO: 20)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lt, err := f.handleRotation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lt) != 1 || !strings.Contains(lt[0].Log, "JOINED (TO: 20)") {
+		t.Fatalf("expected the unterminated line of the rotated file, got %v", lt)
+	}
+	if lt = f.readAvailable(); len(lt) != 1 || !strings.Contains(lt[0].Log, "-> SYNCED") {
+		t.Fatalf("expected the line of the new file, got %v", lt)
+	}
+}
+
+func TestFollowBatchOrdered(t *testing.T) {
+	dir := t.TempDir()
+	files := []*followedFile{}
+	for _, name := range []string{"mysqld.log", "mysqld.log.copy"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		f := openTestFollowedFile(t, path)
+		defer f.file.Close()
+		f.column = "node1"
+		files = append(files, f)
+	}
+
+	// the second file has the oldest event of the batch
+	appendToFile(t, files[0].path, "2001-01-01T01:00:05.000000Z 0 [Note] WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)\n")
+	appendToFile(t, files[1].path, "2001-01-01T01:00:02.000000Z 0 [Note] WSREP: Shifting JOINED -> SYNCED (TO: 20)\n")
+
+	batch := followBatch(files)
+	lt := batch["node1"]
+	if len(batch) != 1 || len(lt) != 2 {
+		t.Fatalf("expected 2 events in a single column, got %v", batch)
+	}
+	if !lt[0].Date.Time.Before(lt[1].Date.Time) {
+		t.Errorf("expected events to be ordered by date, got %s then %s", lt[0].Date.DisplayTime, lt[1].Date.DisplayTime)
+	}
+}
+
+func openTestFollowedFile(t *testing.T, path string) *followedFile {
+	regexes := nativeRegexes(regex.StatesMap)
+	f := &followedFile{
+		path:      path,
+		proc:      newLineProcessor(path, regexes),
+		assembler: &recordAssembler{filter: prepareNativeFilter(regexes)},
+	}
+	if err := f.open(); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func appendToFile(t *testing.T, path, content string) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+}
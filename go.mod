@@ -7,8 +7,10 @@ require (
 	github.com/alecthomas/kong v0.6.1
 	github.com/davecgh/go-spew v1.1.1
 	github.com/google/go-cmp v0.5.9
+	github.com/klauspost/compress v1.16.7
 	github.com/pkg/errors v0.9.1
 	github.com/rs/zerolog v1.29.0
+	github.com/ulikunitz/xz v0.5.11
 	gopkg.in/yaml.v2 v2.4.0
 )
 
@@ -0,0 +1,374 @@
+package input
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+	"github.com/ulikunitz/xz"
+)
+
+// ArchiveSeparator is used to build virtual paths, pointing to a member inside an archive
+// eg: bundle.tar.gz!/node1/mysqld.log
+const ArchiveSeparator = "!/"
+
+var archiveExtensions = []string{".tar", ".tar.gz", ".tgz", ".tar.bz2", ".tar.xz", ".tar.zst", ".zip"}
+
+// ExpandPaths replaces every archive by the virtual paths of the logs it contains
+// Other paths, compressed or not, are kept as-is
+func ExpandPaths(paths []string) ([]string, error) {
+	expanded := []string{}
+	for _, p := range paths {
+		if !IsArchive(p) {
+			expanded = append(expanded, p)
+			continue
+		}
+		members, err := archiveLogMembers(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list archive %s", p)
+		}
+		for _, member := range members {
+			expanded = append(expanded, p+ArchiveSeparator+member)
+		}
+	}
+	return expanded, nil
+}
+
+func IsArchive(p string) bool {
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(p, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPlainFile tells if the path can be read directly, without decompressing or extracting anything
+func IsPlainFile(p string) bool {
+	return !strings.Contains(p, ArchiveSeparator) && decompressionFor(p) == nil
+}
+
+// isLogMember is filtering archives content, as bundles usually have many other files (configs, status, ...)
+// rotated and compressed logs are kept: mysqld.log.2.gz
+func isLogMember(member string) bool {
+	base := path.Base(member)
+	return strings.Contains(base, ".log") || strings.HasSuffix(base, ".err") || base == "logs.txt"
+}
+
+// Open returns a reader on the uncompressed content of the file
+// It also accepts virtual paths to archive members
+func Open(p string) (io.ReadCloser, error) {
+	archive, member, isMember := strings.Cut(p, ArchiveSeparator)
+	if isMember {
+		return openArchiveMember(archive, member)
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	r, err := decompress(p, f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &readCloser{Reader: r, closers: []io.Closer{f}}, nil
+}
+
+// ModTime returns the modification time of the file
+// For archive members, the archive one is used: it can only be more recent than the logs it contains
+func ModTime(p string) (time.Time, error) {
+	info, err := Stat(p)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// Stat is os.Stat, except archive members are given the archive info
+func Stat(p string) (os.FileInfo, error) {
+	archive, _, _ := strings.Cut(p, ArchiveSeparator)
+	return os.Stat(archive)
+}
+
+// sampleLength is how much is hashed at the start and at the end of files by SampleHash
+const sampleLength = 64 * 1024
+
+// SampleHash hashes the first "size" bytes of the file, the archive for archive members
+// Only the start and the end are read: hashing gigabytes of logs would be as slow as searching them
+// It is enough to tell if a file was replaced, or if it only had lines appended
+func SampleHash(p string, size int64) (string, error) {
+	archive, _, _ := strings.Cut(p, ArchiveSeparator)
+	f, err := os.Open(archive)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n", size)
+	head := size
+	if head > sampleLength {
+		head = sampleLength
+	}
+	if _, err := io.Copy(h, io.NewSectionReader(f, 0, head)); err != nil {
+		return "", errors.Wrapf(err, "failed to hash %s", archive)
+	}
+	tailStart := size - sampleLength
+	if tailStart < head {
+		tailStart = head
+	}
+	if _, err := io.Copy(h, io.NewSectionReader(f, tailStart, size-tailStart)); err != nil {
+		return "", errors.Wrapf(err, "failed to hash %s", archive)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+type decompressor func(io.Reader) (io.Reader, error)
+
+func decompressionFor(p string) decompressor {
+	switch {
+	case strings.HasSuffix(p, ".gz"), strings.HasSuffix(p, ".tgz"):
+		return func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) }
+	case strings.HasSuffix(p, ".bz2"):
+		return func(r io.Reader) (io.Reader, error) { return bzip2.NewReader(r), nil }
+	case strings.HasSuffix(p, ".xz"):
+		return func(r io.Reader) (io.Reader, error) { return xz.NewReader(r) }
+	case strings.HasSuffix(p, ".zst"), strings.HasSuffix(p, ".zstd"):
+		return func(r io.Reader) (io.Reader, error) {
+			d, err := zstd.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			return d.IOReadCloser(), nil
+		}
+	}
+	return nil
+}
+
+func decompress(p string, r io.Reader) (io.Reader, error) {
+	d := decompressionFor(p)
+	if d == nil {
+		return r, nil
+	}
+	out, err := d(r)
+	return out, errors.Wrapf(err, "failed to decompress %s", p)
+}
+
+// archiveLogMembers lists the logs of the archive. Tar archives are extracted at the same time
+func archiveLogMembers(archive string) ([]string, error) {
+	if !strings.HasSuffix(archive, ".zip") {
+		e, err := extractTar(archive)
+		if err != nil {
+			return nil, err
+		}
+		return e.order, nil
+	}
+
+	z, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer z.Close()
+	members := []string{}
+	for _, f := range z.File {
+		if !f.FileInfo().IsDir() && isLogMember(f.Name) {
+			members = append(members, f.Name)
+		}
+	}
+	return members, nil
+}
+
+func openTar(archive string) (*tar.Reader, io.Closer, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := decompress(archive, f)
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return tar.NewReader(r), f, nil
+}
+
+func openArchiveMember(archive, member string) (io.ReadCloser, error) {
+
+	if strings.HasSuffix(archive, ".zip") {
+		z, err := zip.OpenReader(archive)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range z.File {
+			if f.Name != member {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				z.Close()
+				return nil, err
+			}
+			r, err := decompress(member, rc)
+			if err != nil {
+				rc.Close()
+				z.Close()
+				return nil, err
+			}
+			return &readCloser{Reader: r, closers: []io.Closer{rc, z}}, nil
+		}
+		z.Close()
+		return nil, errors.Errorf("%s not found in %s", member, archive)
+	}
+
+	e, err := extractTar(archive)
+	if err != nil {
+		return nil, err
+	}
+	extracted, ok := e.members[member]
+	if !ok {
+		return nil, errors.Errorf("%s not found in %s", member, archive)
+	}
+	f, err := os.Open(extracted)
+	if err != nil {
+		return nil, err
+	}
+	r, err := decompress(member, f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &readCloser{Reader: r, closers: []io.Closer{f}}, nil
+}
+
+// tar archives can't be accessed randomly: going through it for each member would decompress it again and again
+// Their logs are extracted once, in a temporary directory removed by Cleanup
+var tarExtractions = struct {
+	sync.Mutex
+	byArchive map[string]*tarExtraction
+	dirs      []string
+}{byArchive: map[string]*tarExtraction{}}
+
+type tarExtraction struct {
+	once    sync.Once
+	members map[string]string // member name -> extracted file
+	order   []string
+	err     error
+}
+
+func extractTar(archive string) (*tarExtraction, error) {
+	tarExtractions.Lock()
+	e, ok := tarExtractions.byArchive[archive]
+	if !ok {
+		e = &tarExtraction{}
+		tarExtractions.byArchive[archive] = e
+	}
+	tarExtractions.Unlock()
+
+	e.once.Do(func() {
+		e.err = e.extract(archive)
+	})
+	return e, e.err
+}
+
+// extract copies the log members as they are, compressed members are decompressed when opened
+func (e *tarExtraction) extract(archive string) error {
+	tr, closer, err := openTar(archive)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	dir, err := os.MkdirTemp("", "galera-log-explainer-")
+	if err != nil {
+		return errors.Wrap(err, "failed to create a directory to extract archives")
+	}
+	tarExtractions.Lock()
+	tarExtractions.dirs = append(tarExtractions.dirs, dir)
+	tarExtractions.Unlock()
+
+	e.members = map[string]string{}
+	extractedCount := 0
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg || !isLogMember(header.Name) {
+			continue
+		}
+		// member names are not used in the extracted path, they could point outside of the directory
+		extracted := filepath.Join(dir, strconv.Itoa(extractedCount))
+		extractedCount++
+		if err := copyToFile(extracted, tr); err != nil {
+			return errors.Wrapf(err, "failed to extract %s", header.Name)
+		}
+		// a member can be appended several times to a tar, the last one wins as it would with "tar -x"
+		if _, ok := e.members[header.Name]; !ok {
+			e.order = append(e.order, header.Name)
+		}
+		e.members[header.Name] = extracted
+	}
+}
+
+func copyToFile(p string, r io.Reader) error {
+	f, err := os.Create(p)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Cleanup removes the logs extracted from archives
+func Cleanup() error {
+	tarExtractions.Lock()
+	defer tarExtractions.Unlock()
+	var err error
+	for _, dir := range tarExtractions.dirs {
+		if rerr := os.RemoveAll(dir); rerr != nil && err == nil {
+			err = rerr
+		}
+	}
+	tarExtractions.dirs = nil
+	tarExtractions.byArchive = map[string]*tarExtraction{}
+	return err
+}
+
+// readCloser closes every underlying layer: decompressors, archive members, archives, files
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (rc *readCloser) Close() error {
+	var err error
+	if c, ok := rc.Reader.(io.Closer); ok {
+		err = c.Close()
+	}
+	for _, c := range rc.closers {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,279 @@
+package input
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+const content = "2001-01-01T01:01:01.000000Z 0 [Note] WSREP: Shifting OPEN -> PRIMARY (TO: 1)\n"
+
+func TestExpandAndOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	gzPath := filepath.Join(dir, "mysqld.log.2.gz")
+	writeGzip(t, gzPath, []byte(content))
+
+	tarPath := filepath.Join(dir, "bundle.tar.gz")
+	writeTarGz(t, tarPath, map[string][]byte{
+		"node1/mysqld.log":      []byte(content),
+		"node1/my.cnf":          []byte("[mysqld]\n"),
+		"node2/mysqld.log.1.gz": gzipBytes(t, []byte(content)),
+	})
+
+	zipPath := filepath.Join(dir, "bundle.zip")
+	writeZip(t, zipPath, map[string][]byte{
+		"node3/mysqld-error.log": []byte(content),
+		"node3/status.txt":       []byte("nothing\n"),
+	})
+
+	paths, err := ExpandPaths([]string{gzPath, tarPath, zipPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		gzPath,
+		tarPath + "!/node1/mysqld.log",
+		tarPath + "!/node2/mysqld.log.1.gz",
+		zipPath + "!/node3/mysqld-error.log",
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("expected %v, got %v", expected, paths)
+	}
+
+	for _, p := range paths {
+		r, err := Open(p)
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", p, err)
+		}
+		out, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", p, err)
+		}
+		r.Close()
+		if string(out) != content {
+			t.Errorf("%s: expected %q, got %q", p, content, out)
+		}
+	}
+
+	if _, err := Open(tarPath + "!/node4/mysqld.log"); err == nil {
+		t.Errorf("opening a missing member should fail")
+	}
+}
+
+func TestTarExtractedOnce(t *testing.T) {
+	defer Cleanup()
+	dir := t.TempDir()
+
+	tarPath := filepath.Join(dir, "bundle.tar.gz")
+	writeTarGz(t, tarPath, map[string][]byte{
+		"node1/mysqld.log":      []byte(content),
+		"node2/mysqld.log.1.gz": gzipBytes(t, []byte(content)),
+	})
+	paths, err := ExpandPaths([]string{tarPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// members are read from the extraction, the archive is not needed anymore
+	if err := os.Remove(tarPath); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range paths {
+		r, err := Open(p)
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", p, err)
+		}
+		out, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", p, err)
+		}
+		if string(out) != content {
+			t.Errorf("%s: expected %q, got %q", p, content, out)
+		}
+	}
+
+	if err := Cleanup(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(paths[0]); err == nil {
+		t.Errorf("opening a member of a removed archive should fail after Cleanup")
+	}
+}
+
+func TestTarDuplicatedMember(t *testing.T) {
+	defer Cleanup()
+	tarPath := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	writeTarGzMembers(t, tarPath, []tarMember{
+		{name: "node1/mysqld.log", content: []byte("first node1\n")},
+		{name: "node1/mysqld.log", content: []byte("last node1\n")},
+		{name: "node2/mysqld.log", content: []byte("node2\n")},
+	})
+
+	paths, err := ExpandPaths([]string{tarPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{tarPath + "!/node1/mysqld.log", tarPath + "!/node2/mysqld.log"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("expected %v, got %v", expected, paths)
+	}
+
+	// the last duplicate wins, like "tar -x" would do
+	for i, content := range []string{"last node1\n", "node2\n"} {
+		r, err := Open(paths[i])
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", paths[i], err)
+		}
+		out, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", paths[i], err)
+		}
+		if string(out) != content {
+			t.Errorf("%s: expected %q, got %q", paths[i], content, out)
+		}
+	}
+}
+
+func gzipBytes(t *testing.T, b []byte) []byte {
+	path := filepath.Join(t.TempDir(), "tmp.gz")
+	writeGzip(t, path, b)
+	out, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func writeGzip(t *testing.T, path string, b []byte) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := gzip.NewWriter(f)
+	defer w.Close()
+	if _, err := w.Write(b); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTarGz(t *testing.T, path string, files map[string][]byte) {
+	members := []tarMember{}
+	for _, name := range sortedNames(files) {
+		members = append(members, tarMember{name: name, content: files[name]})
+	}
+	writeTarGzMembers(t, path, members)
+}
+
+type tarMember struct {
+	name    string
+	content []byte
+}
+
+// writeTarGzMembers keeps the given order, members can be repeated
+func writeTarGzMembers(t *testing.T, path string, members []tarMember) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gw := gzip.NewWriter(f)
+	defer gw.Close()
+	tw := tar.NewWriter(gw)
+	defer tw.Close()
+
+	for _, m := range members {
+		err := tw.WriteHeader(&tar.Header{Name: m.name, Mode: 0644, Size: int64(len(m.content)), Typeflag: tar.TypeReg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(m.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func writeZip(t *testing.T, path string, files map[string][]byte) {
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	defer zw.Close()
+
+	for _, name := range sortedNames(files) {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(files[name]); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func sortedNames(files map[string][]byte) []string {
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestSampleHash(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "mysqld.log")
+	long := strings.Repeat(content, 2000) // longer than what is sampled
+	if err := os.WriteFile(p, []byte(long), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	before, err := SampleHash(p, int64(len(long)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.OpenFile(p, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(content)
+	f.Close()
+
+	appended, err := SampleHash(p, int64(len(long)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if appended != before {
+		t.Errorf("appending lines should not change the hash of the previous content")
+	}
+	whole, err := SampleHash(p, int64(len(long)+len(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if whole == before {
+		t.Errorf("hashes of different sizes should be different")
+	}
+
+	if err := os.WriteFile(p, []byte(strings.Replace(long, "PRIMARY", "primary", 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	replaced, err := SampleHash(p, int64(len(long)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replaced == before {
+		t.Errorf("a modified start of file should change the hash")
+	}
+}
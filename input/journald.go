@@ -0,0 +1,167 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Input formats, given by --input-format
+const (
+	FormatAuto         = "auto"
+	FormatErrorLog     = "error-log"
+	FormatJournaldJSON = "journald-json"
+)
+
+// journald entries are converted to journald "short-iso-precise" lines, which are handled like syslog ones
+const journaldLayout = "2006-01-02T15:04:05.000000-0700"
+
+// maxEntryLength is the longest journald entry we accept, some messages are very long state dumps
+const maxEntryLength = 64 * 1024 * 1024
+
+// OpenFormat is the same as Open, but the content is converted to text logs when needed
+// "auto" will peek at the start of the content to find the format
+func OpenFormat(p, format string) (io.ReadCloser, error) {
+	rc, err := Open(p)
+	if err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(rc)
+	if format == FormatAuto {
+		format = detectFormat(r)
+	}
+	if format == FormatJournaldJSON {
+		return &readCloser{Reader: newJournaldReader(r), closers: []io.Closer{rc}}, nil
+	}
+	return &readCloser{Reader: r, closers: []io.Closer{rc}}, nil
+}
+
+func detectFormat(r *bufio.Reader) string {
+	// a journald entry always has this field, and it comes early
+	start, _ := r.Peek(4096)
+	if bytes.HasPrefix(bytes.TrimSpace(start), []byte("{")) && bytes.Contains(start, []byte(`"__REALTIME_TIMESTAMP"`)) {
+		return FormatJournaldJSON
+	}
+	return FormatErrorLog
+}
+
+type journaldEntry struct {
+	Message    json.RawMessage `json:"MESSAGE"`
+	Timestamp  string          `json:"__REALTIME_TIMESTAMP"`
+	Hostname   string          `json:"_HOSTNAME"`
+	Unit       string          `json:"_SYSTEMD_UNIT"`
+	Identifier string          `json:"SYSLOG_IDENTIFIER"`
+	PID        string          `json:"_PID"`
+}
+
+// JournaldEntryToLines converts a "journalctl -o json" entry to the equivalent syslog lines
+// eg: 2023-01-05T03:24:26.123456+0000 db1 mysql.service[123]: <message>
+// Multi-line messages get a line each, like rsyslog would do
+func JournaldEntryToLines(entry []byte) ([]string, error) {
+	var e journaldEntry
+	if err := json.Unmarshal(entry, &e); err != nil {
+		return nil, errors.Wrap(err, "invalid journald entry")
+	}
+
+	usec, err := strconv.ParseInt(e.Timestamp, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid journald timestamp")
+	}
+	message, err := journaldMessage(e.Message)
+	if err != nil {
+		return nil, err
+	}
+
+	host := e.Hostname
+	if host == "" {
+		host = "localhost"
+	}
+	process := e.Unit
+	if process == "" {
+		process = e.Identifier
+	}
+	if process == "" {
+		process = "mysqld"
+	}
+	if e.PID != "" {
+		process += "[" + e.PID + "]"
+	}
+	prefix := time.Unix(0, usec*int64(time.Microsecond)).UTC().Format(journaldLayout) + " " + host + " " + process + ": "
+
+	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+	for i := range lines {
+		lines[i] = prefix + lines[i]
+	}
+	return lines, nil
+}
+
+// MESSAGE is a string, unless it was not valid utf8: it is then given as an array of bytes
+func journaldMessage(raw json.RawMessage) (string, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+	var b []byte
+	var ints []int
+	if err := json.Unmarshal(raw, &ints); err != nil {
+		return "", errors.Wrap(err, "invalid journald message")
+	}
+	for _, i := range ints {
+		b = append(b, byte(i))
+	}
+	return string(b), nil
+}
+
+// journaldReader converts journald entries on the fly
+type journaldReader struct {
+	scanner *bufio.Scanner
+	pending []byte
+}
+
+func newJournaldReader(r io.Reader) *journaldReader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), maxEntryLength)
+	return &journaldReader{scanner: s}
+}
+
+func (r *journaldReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if !r.scanner.Scan() {
+			if err := r.scanner.Err(); err != nil {
+				return 0, err
+			}
+			return 0, io.EOF
+		}
+		lines, err := JournaldEntryToLines(r.scanner.Bytes())
+		if err != nil {
+			// journalctl can add cursors or errors in its output, they are not entries
+			continue
+		}
+		for _, line := range lines {
+			r.pending = append(r.pending, line...)
+			r.pending = append(r.pending, '\n')
+		}
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// IsJournaldJSON tells if the file has to be read as a journald export
+func IsJournaldJSON(p, format string) bool {
+	if format != FormatAuto {
+		return format == FormatJournaldJSON
+	}
+	rc, err := Open(p)
+	if err != nil {
+		return false
+	}
+	defer rc.Close()
+	return detectFormat(bufio.NewReader(rc)) == FormatJournaldJSON
+}
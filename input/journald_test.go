@@ -0,0 +1,103 @@
+package input
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestJournaldEntryToLines(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		expected  []string
+		expectErr bool
+	}{
+		{
+			name:     "simple entry",
+			input:    `{"__REALTIME_TIMESTAMP":"1672889066184707","_HOSTNAME":"db1","_SYSTEMD_UNIT":"mysql.service","_PID":"123","MESSAGE":"WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 12)"}`,
+			expected: []string{"2023-01-05T03:24:26.184707+0000 db1 mysql.service[123]: WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 12)"},
+		},
+		{
+			name:  "multi-line message, without unit",
+			input: `{"__REALTIME_TIMESTAMP":"1672889066184707","_HOSTNAME":"db1","SYSLOG_IDENTIFIER":"mysqld","MESSAGE":"View:\n  id: abc:12\n"}`,
+			expected: []string{
+				"2023-01-05T03:24:26.184707+0000 db1 mysqld: View:",
+				"2023-01-05T03:24:26.184707+0000 db1 mysqld:   id: abc:12",
+			},
+		},
+		{
+			name:     "non-utf8 message given as bytes",
+			input:    `{"__REALTIME_TIMESTAMP":"1672889066184707","MESSAGE":[104,105]}`,
+			expected: []string{"2023-01-05T03:24:26.184707+0000 localhost mysqld: hi"},
+		},
+		{
+			name:      "not an entry",
+			input:     `-- cursor: s=abc`,
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		out, err := JournaldEntryToLines([]byte(test.input))
+		if (err != nil) != test.expectErr {
+			t.Fatalf("testname: %s, unexpected error: %v", test.name, err)
+		}
+		if !test.expectErr && !reflect.DeepEqual(out, test.expected) {
+			t.Errorf("testname: %s, expected: %v, got: %v", test.name, test.expected, out)
+		}
+	}
+}
+
+func TestOpenFormat(t *testing.T) {
+	dir := t.TempDir()
+	journal := filepath.Join(dir, "journal.json")
+	entries := `{"__CURSOR":"s=abc","__REALTIME_TIMESTAMP":"1672889066184707","_HOSTNAME":"db1","MESSAGE":"first"}
+not json
+{"__CURSOR":"s=abd","__REALTIME_TIMESTAMP":"1672889067184707","_HOSTNAME":"db1","MESSAGE":"second"}
+`
+	if err := os.WriteFile(journal, []byte(entries), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	errorLog := filepath.Join(dir, "mysqld.log")
+	if err := os.WriteFile(errorLog, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path     string
+		format   string
+		expected string
+		journald bool
+	}{
+		{
+			path:   journal,
+			format: FormatAuto,
+			expected: "2023-01-05T03:24:26.184707+0000 db1 mysqld: first\n" +
+				"2023-01-05T03:24:27.184707+0000 db1 mysqld: second\n",
+			journald: true,
+		},
+		{path: journal, format: FormatErrorLog, expected: entries},
+		{path: errorLog, format: FormatAuto, expected: content},
+	}
+
+	for _, test := range tests {
+		rc, err := OpenFormat(test.path, test.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != test.expected {
+			t.Errorf("path: %s, format: %s, expected: %q, got: %q", test.path, test.format, test.expected, string(b))
+		}
+		if IsJournaldJSON(test.path, test.format) != test.journald {
+			t.Errorf("path: %s, format: %s, wrong IsJournaldJSON", test.path, test.format)
+		}
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"os/exec"
 	"runtime"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
+	"github.com/ylacancellera/galera-log-explainer/input"
 	"github.com/ylacancellera/galera-log-explainer/regex"
 	"github.com/ylacancellera/galera-log-explainer/types"
 	"github.com/ylacancellera/galera-log-explainer/utils"
@@ -31,36 +34,65 @@ func timelineFromPaths(paths []string, regexes types.RegexMap) (types.Timeline,
 	timeline := make(types.Timeline)
 	found := false
 
-	compiledRegex := prepareGrepArgument(regexes)
+	// archives are replaced by their members, so that each of them are handled as a regular log
+	paths, err := input.ExpandPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+	regexStats.setOrder(paths)
 
-	for _, path := range paths {
-		stdout := make(chan string)
+	var search func(path string, stdout chan<- searchResult) error
+	switch CLI.Engine {
+	case "grep":
+		if CLI.CacheDir != "" {
+			return nil, errors.New("--cache-dir is only supported by the native engine")
+		}
+		compiledRegex := prepareGrepArgument(regexes)
+		search = func(path string, stdout chan<- searchResult) error {
+			return execGrepAndIterate(path, compiledRegex, stdout)
+		}
+	default:
+		regexes = nativeRegexes(regexes)
+		filter := prepareNativeFilter(regexes)
+		search = func(path string, stdout chan<- searchResult) error {
+			return execNativeAndIterate(path, filter, stdout)
+		}
+		if CLI.CacheDir != "" {
+			cache, err := newExtractionCache(CLI.CacheDir)
+			if err != nil {
+				return nil, err
+			}
+			search = cache.search
+		}
+	}
 
+	// files are extracted concurrently, but merged in the order they were given
+	// merges depend on the order, this is needed to get the same output as a sequential run
+	localTimelines := make([]types.LocalTimeline, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < jobCount(len(paths)); i++ {
+		wg.Add(1)
 		go func() {
-			err := execGrepAndIterate(path, compiledRegex, stdout)
-			if err != nil {
-				logger.Error().Str("path", path).Err(err).Msg("execGrepAndIterate returned error")
+			defer wg.Done()
+			for j := range jobs {
+				localTimelines[j] = extractFromPath(paths[j], regexes, search)
 			}
 		}()
+	}
+	for j := range paths {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
 
-		// it will iterate on stdout pipe results
-		localTimeline, err := iterateOnGrepResults(path, regexes, stdout)
-		if err != nil {
-			logger.Warn().Err(err).Msg("Failed to iterate on results")
+	for i, path := range paths {
+		localTimeline := localTimelines[i]
+		if len(localTimeline) == 0 {
+			continue
 		}
 		found = true
-		logger.Debug().Str("path", path).Msg("Finished searching")
-
-		// Why it should not just identify using the file path:
-		// so that we are able to merge files that belong to the same nodes
-		// we wouldn't want them to be shown as from different nodes
-		if CLI.PxcOperator {
-			timeline[path] = localTimeline
-		} else if CLI.MergeByDirectory {
-			timeline.MergeByDirectory(path, localTimeline)
-		} else {
-			timeline.MergeByIdentifier(localTimeline)
-		}
+		mergeLocalTimeline(timeline, path, localTimeline)
 	}
 	if !found {
 		return nil, errors.New("Could not find data")
@@ -68,9 +100,61 @@ func timelineFromPaths(paths []string, regexes types.RegexMap) (types.Timeline,
 	return timeline, nil
 }
 
+// mergeLocalTimeline adds the events of a file to the timeline, in the column of the node it comes from
+func mergeLocalTimeline(timeline types.Timeline, path string, localTimeline types.LocalTimeline) {
+	// Why it should not just identify using the file path:
+	// so that we are able to merge files that belong to the same nodes
+	// we wouldn't want them to be shown as from different nodes
+	if CLI.PxcOperator {
+		timeline[path] = localTimeline
+	} else if CLI.MergeByDirectory {
+		timeline.MergeByDirectory(path, localTimeline)
+	} else {
+		timeline.MergeByIdentifier(localTimeline)
+	}
+}
+
+// extractFromPath searches a single file and builds its own timeline
+func extractFromPath(path string, regexes types.RegexMap, search func(string, chan<- searchResult) error) types.LocalTimeline {
+	stdout := make(chan searchResult)
+
+	go func() {
+		err := search(path, stdout)
+		if err != nil {
+			logger.Error().Str("path", path).Err(err).Msg("search returned error")
+		}
+	}()
+
+	// it will iterate on stdout pipe results
+	localTimeline, err := iterateOnGrepResults(path, regexes, stdout)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Failed to iterate on results")
+	}
+
+	// iteration can stop early because of --until, the search still has to finish to avoid leaking it
+	for range stdout {
+	}
+	logger.Debug().Str("path", path).Msg("Finished searching")
+	return localTimeline
+}
+
+// jobCount returns how many files can be extracted at the same time
+func jobCount(pathCount int) int {
+	jobs := CLI.Jobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
+	if jobs > pathCount {
+		jobs = pathCount
+	}
+	return jobs
+}
+
 func prepareGrepArgument(regexes types.RegexMap) string {
 
-	regexToSendSlice := regexes.Compile()
+	// grep only sends lines in isolation, records can't be matched
+	lines, _ := regexes.SplitMultiLine()
+	regexToSendSlice := lines.Compile()
 
 	grepRegex := "^"
 	if CLI.PxcOperator {
@@ -82,7 +166,9 @@ func prepareGrepArgument(regexes types.RegexMap) string {
 		regexes.Merge(regex.PXCOperatorMap)
 	}
 	if CLI.Since != nil {
-		grepRegex += "(" + regex.BetweenDateRegex(CLI.Since, CLI.PxcOperator) + "|" + regex.NoDatesRegex(CLI.PxcOperator) + ")"
+		// dates without timezone can be a day behind --since, the exact filtering is done later anyway
+		since := CLI.Since.AddDate(0, 0, -1)
+		grepRegex += "(" + regex.BetweenDateRegex(&since, CLI.PxcOperator) + "|" + regex.NoDatesRegex(CLI.PxcOperator) + ")"
 	}
 	grepRegex += ".*"
 	grepRegex += "(" + strings.Join(regexToSendSlice, "|") + ")"
@@ -93,18 +179,24 @@ func prepareGrepArgument(regexes types.RegexMap) string {
 	return grepRegex
 }
 
-func execGrepAndIterate(path, compiledRegex string, stdout chan<- string) error {
+// searchResult is what search engines send to be processed
+// it is either a single line, or a whole multi-line record for MultiLine regexes
+type searchResult struct {
+	text   string
+	record bool
+}
+
+func execGrepAndIterate(path, compiledRegex string, stdout chan<- searchResult) error {
 
 	defer close(stdout)
 
 	// A first pass is done, with every regexes we want compiled in a single one.
 
 	/*
-		Regular grep is actually used
+		This is the opt-in engine (--engine=grep), the native one is used by default
 
-		There are no great alternatives, even less as golang libraries.
-		grep itself do not have great alternatives: they are less performant for common use-cases, or are not easily portable, or are costlier to execute.
-		grep is everywhere, grep is good enough, it even enable to use the stdout pipe.
+		It can still be useful to compare results, or to benefit from a specific grep build.
+		It is not available everywhere though: it requires perl regexes (-P), which macOS and BusyBox greps do not have.
 
 		The usual bottleneck with grep is that it is single-threaded, but we actually benefit
 		from a sequential scan here as we will rely on the log order.
@@ -113,11 +205,22 @@ func execGrepAndIterate(path, compiledRegex string, stdout chan<- string) error
 		It also helps to be transparent and not provide an obscure tool that work as a blackbox
 	*/
 	if runtime.GOOS == "darwin" && CLI.GrepCmd == "grep" {
-		logger.Warn().Msg("On Darwin systems, use 'pt-galera-log-explainer --grep-cmd=ggrep' as it requires grep v3")
+		logger.Warn().Msg("On Darwin systems, use 'galera-log-explainer --engine=grep --grep-cmd=ggrep' as it requires grep v3")
 	}
 
 	cmd := exec.Command(CLI.GrepCmd, CLI.GrepArgs, compiledRegex, path)
 
+	// grep can't read compressed files, archives and journald exports, their content is sent through stdin instead
+	if !input.IsPlainFile(path) || input.IsJournaldJSON(path, CLI.InputFormat) {
+		f, err := input.OpenFormat(path, CLI.InputFormat)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open %s", path)
+		}
+		defer f.Close()
+		cmd = exec.Command(CLI.GrepCmd, CLI.GrepArgs, compiledRegex, "-")
+		cmd.Stdin = f
+	}
+
 	out, _ := cmd.StdoutPipe()
 	defer out.Close()
 
@@ -129,7 +232,7 @@ func execGrepAndIterate(path, compiledRegex string, stdout chan<- string) error
 	// grep treatment
 	s := bufio.NewScanner(out)
 	for s.Scan() {
-		stdout <- s.Text()
+		stdout <- searchResult{text: s.Text()}
 	}
 
 	// double-check it stopped correctly
@@ -153,50 +256,150 @@ func sanitizeLine(s string) string {
 // iterateOnGrepResults will take line by line each logs that matched regex
 // it will iterate on every regexes in slice, and apply the handler for each
 // it also filters out --since and --until rows
-func iterateOnGrepResults(path string, regexes types.RegexMap, grepStdout <-chan string) (types.LocalTimeline, error) {
+func iterateOnGrepResults(path string, regexes types.RegexMap, grepStdout <-chan searchResult) (types.LocalTimeline, error) {
 
 	var (
-		lt           types.LocalTimeline
-		recentEnough bool
-		displayer    types.LogDisplayer
+		lt   types.LocalTimeline
+		more = true
 	)
+	p := newLineProcessor(path, regexes)
+	regexStats.track(path, p)
+
+	for result := range grepStdout {
+		lt, more = p.processResult(lt, result)
+		if !more {
+			break
+		}
+	}
+	return lt, nil
+}
+
+// lineProcessor translates the lines of a single file into events
+// The context is kept between lines, so that a file can also be processed progressively
+type lineProcessor struct {
+	regexes      types.RegexMap
+	lineKeys     []string
+	recordKeys   []string
+	ctx          types.LogCtx
+	recentEnough bool
+	stats        map[string]*types.RegexStat // only collected for regex-list --stats
+	overlaps     types.RegexOverlaps         // only collected for regex-list --overlaps
+}
+
+func newLineProcessor(path string, regexes types.RegexMap) *lineProcessor {
 	ctx := types.NewLogCtx()
 	ctx.FilePath = path
+	ctx.YearReference = yearReference(path)
+	ctx.Location = locationForPath(path)
+	lines, records := regexes.SplitMultiLine()
+	return &lineProcessor{
+		regexes:    regexes,
+		lineKeys:   lines.OrderedKeys(),
+		recordKeys: records.OrderedKeys(),
+		ctx:        ctx,
+	}
+}
+
+// node is the identifier of the file, the same way columns are attributed in "list"
+func (p *lineProcessor) node() string {
+	if CLI.PxcOperator {
+		return p.ctx.FilePath
+	}
+	if CLI.MergeByDirectory {
+		return types.DirectoryIdentifier(p.ctx.FilePath)
+	}
+	return types.Identifier(p.ctx)
+}
 
-	for line := range grepStdout {
-		line = sanitizeLine(line)
+// yearReference is used to complete dates that do not have years
+func yearReference(path string) time.Time {
+	if CLI.SyslogYear != 0 {
+		return time.Date(CLI.SyslogYear, time.December, 31, 23, 59, 59, 0, time.UTC)
+	}
+	mtime, err := input.ModTime(path)
+	if err != nil {
+		logger.Warn().Str("path", path).Err(err).Msg("failed to get modification time, dates without years will be considered from this year")
+		return time.Now()
+	}
+	return mtime
+}
 
-		var date *types.Date
-		t, layout, ok := regex.SearchDateFromLog(line)
-		if ok {
-			date = types.NewDate(t, layout)
-		}
+func (p *lineProcessor) processResult(lt types.LocalTimeline, result searchResult) (types.LocalTimeline, bool) {
+	if result.record {
+		return p.processRecord(lt, result.text)
+	}
+	return p.process(lt, result.text)
+}
+
+// process adds the events found on the line to the local timeline
+// it returns false when --until is reached, as no more events are expected
+func (p *lineProcessor) process(lt types.LocalTimeline, line string) (types.LocalTimeline, bool) {
+	return p.handle(lt, sanitizeLine(line), p.lineKeys)
+}
+
+// processRecord is the same as process, for a multi-line record. Only MultiLine regexes are used
+func (p *lineProcessor) processRecord(lt types.LocalTimeline, record string) (types.LocalTimeline, bool) {
+	return p.handle(lt, record, p.recordKeys)
+}
+
+func (p *lineProcessor) handle(lt types.LocalTimeline, line string, keys []string) (types.LocalTimeline, bool) {
+	var displayer types.LogDisplayer
+
+	date := regex.DateFromLog(p.ctx, line)
+
+	// If it's recentEnough, it means we already validated a log: every next logs necessarily happened later
+	// this is useful because not every logs have a date attached, and some without date are very useful
+	if !p.recentEnough && CLI.Since != nil && (date == nil || (date != nil && CLI.Since.After(date.Time))) {
+		return lt, true
+	}
+	if CLI.Until != nil && date != nil && CLI.Until.Before(date.Time) {
+		return lt, false
+	}
+	p.recentEnough = true
 
-		// If it's recentEnough, it means we already validated a log: every next logs necessarily happened later
-		// this is useful because not every logs have a date attached, and some without date are very useful
-		if !recentEnough && CLI.Since != nil && (date == nil || (date != nil && CLI.Since.After(date.Time))) {
+	// syslog and journald give the hostname, which is not always found in mysql logs
+	if host, ok := regex.SyslogHost(line); ok {
+		p.ctx.SetHostname(host)
+	}
+	line = regex.StripSyslogPrefix(line)
+	filetype := regex.FileType(line, CLI.PxcOperator)
+	p.ctx.FileType = filetype
+
+	// We have to find again what regex worked to get this log line
+	// it can match multiple regexes, they are applied in order until a terminal one
+	displayed := []string{}
+	for _, key := range keys {
+		regex := p.regexes[key]
+		if !regex.Regex.MatchString(line) || utils.SliceContains(CLI.ExcludeRegexes, key) {
 			continue
 		}
-		if CLI.Until != nil && date != nil && CLI.Until.Before(date.Time) {
-			return lt, nil
+		p.ctx, displayer = regex.Handle(p.ctx, line)
+		if p.stats != nil {
+			p.addStat(key, regex, date, line, displayer)
 		}
-		recentEnough = true
-
-		filetype := regex.FileType(line, CLI.PxcOperator)
-		ctx.FileType = filetype
-
-		// We have to find again what regex worked to get this log line
-		// it can match multiple regexes
-		for key, regex := range regexes {
-			if !regex.Regex.MatchString(line) || utils.SliceContains(CLI.ExcludeRegexes, key) {
-				continue
-			}
-			ctx, displayer = regex.Handle(ctx, line)
-			li := types.NewLogInfo(date, displayer, line, regex, key, ctx, filetype)
+		if displayer != nil {
+			displayed = append(displayed, key)
+		}
+		li := types.NewLogInfo(date, displayer, line, regex, key, p.ctx, filetype)
+		p.highlightResourceConsequence(lt, &li, key)
 
-			lt = lt.Add(li)
+		lt = lt.Add(li)
+		if regex.Terminates(line) {
+			break
 		}
+	}
+	if p.overlaps != nil && len(displayed) > 1 {
+		p.overlaps.Add(displayed, line)
+	}
+	return lt, true
+}
 
+// highlightResourceConsequence warns when a crash or a failed SST directly follows the node running out of something
+func (p *lineProcessor) highlightResourceConsequence(lt types.LocalTimeline, li *types.LogInfo, key string) {
+	if !regex.IsResourceConsequence(key) {
+		return
+	}
+	if exhaustion, ok := lt.PrecedingResourceExhaustion(CLI.Verbosity); ok {
+		li.Highlight("after running out of " + exhaustion.Resource)
 	}
-	return lt, nil
 }
@@ -3,6 +3,7 @@ package main
 import (
 	"github.com/pkg/errors"
 	"github.com/ylacancellera/galera-log-explainer/display"
+	"github.com/ylacancellera/galera-log-explainer/input"
 	"github.com/ylacancellera/galera-log-explainer/regex"
 	"github.com/ylacancellera/galera-log-explainer/types"
 )
@@ -11,12 +12,17 @@ type list struct {
 	// Paths is duplicated because it could not work as variadic with kong cli if I set it as CLI object
 	Paths                  []string `arg:"" name:"paths" help:"paths of the log to use"`
 	SkipStateColoredColumn bool     `help:"avoid having the placeholder colored with mysql state, which is guessed using several regexes that will not be displayed"`
-	All                    bool     `help:"List everything" xor:"states,views,events,sst,applicative"`
+	All                    bool     `help:"List everything" xor:"states,views,events,sst,applicative,resources,network,ddl"`
 	States                 bool     `help:"List WSREP state changes(SYNCED, DONOR, ...)" xor:"states"`
 	Views                  bool     `help:"List how Galera views evolved (who joined, who left)" xor:"views"`
 	Events                 bool     `help:"List generic mysql events (start, shutdown, assertion failures)" xor:"events"`
 	SST                    bool     `help:"List Galera synchronization event" xor:"sst"`
 	Applicative            bool     `help:"List applicative events (resyncs, desyncs, conflicts). Events tied to one's usage of Galera" xor:"applicative"`
+	Resources              bool     `help:"List resource exhaustions (disk full, out of memory, too many open files)" xor:"resources"`
+	Network                bool     `help:"List network issues (wrong group, TLS handshakes, unreachable peers)" xor:"network"`
+	DDL                    bool     `help:"List schema changes (TOI, RSU, NBO) and how long they impacted the cluster" xor:"ddl"`
+	Follow                 bool     `help:"Keep watching the files, like 'tail -F', and print new events as they come"`
+	CorrectSkew            bool     `help:"Move the dates of each node to the same clock, using the skew estimated from events every nodes logged. See 'galera-log-explainer skew'"`
 }
 
 func (l *list) Help() string {
@@ -30,24 +36,52 @@ Usage:
 	galera-log-explainer list --all *.log
 	galera-log-explainer list --sst --views --states <list of files>
 	galera-log-explainer list --events --views *.log
+	galera-log-explainer list --all --follow /var/log/mysql/*.log
+	galera-log-explainer list --all --correct-skew *.log
 	`
 }
 
 func (l *list) Run() error {
 
-	if !(l.All || l.Events || l.States || l.SST || l.Views || l.Applicative) {
-		return errors.New("Please select a type of logs to search: --all, or any parameters from: --sst --views --events --states")
+	if !(l.All || l.Events || l.States || l.SST || l.Views || l.Applicative || l.Resources || l.Network || l.DDL) {
+		return errors.New("Please select a type of logs to search: --all, or any parameters from: --sst --views --events --states --applicative --resources --network --ddl")
 	}
 
 	toCheck := l.regexesToUse()
 
+	if l.Follow {
+		if l.CorrectSkew {
+			return errors.New("--correct-skew can't be used with --follow")
+		}
+		paths, err := input.ExpandPaths(CLI.List.Paths)
+		if err != nil {
+			return err
+		}
+		return followPaths(paths, toCheck, CLI.Verbosity)
+	}
+
 	timeline, err := timelineFromPaths(CLI.List.Paths, toCheck)
 	if err != nil {
 		return errors.Wrap(err, "Could not list events")
 	}
 
+	if l.CorrectSkew {
+		timeline.CorrectClockSkews(types.EstimateClockSkews(regex.ClockAnchors(timeline)))
+	}
+
+	// computed first, the timeline is modified to be displayed
+	latestCtxs := timeline.GetLatestUpdatedContextsByNodes()
+
 	display.TimelineCLI(timeline, CLI.Verbosity)
 
+	if l.Applicative || l.All {
+		display.FlowControlSummary(latestCtxs)
+		display.LargeTransactionsSummary(latestCtxs)
+	}
+	if l.Network || l.All {
+		display.NetworkIssuesSummary(latestCtxs)
+	}
+
 	return nil
 }
 
@@ -70,6 +104,15 @@ func (l *list) regexesToUse() types.RegexMap {
 	if l.Applicative || l.All {
 		toCheck.Merge(regex.ApplicativeMap)
 	}
+	if l.Resources || l.All {
+		toCheck.Merge(regex.ResourcesMap)
+	}
+	if l.Network || l.All {
+		toCheck.Merge(regex.NetworkMap)
+	}
+	if l.DDL || l.All {
+		toCheck.Merge(regex.DDLMap)
+	}
 	if l.Events || l.All {
 		toCheck.Merge(regex.EventsMap)
 	} else if !l.SkipStateColoredColumn {
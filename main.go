@@ -8,6 +8,8 @@ import (
 	"github.com/alecthomas/kong"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"github.com/ylacancellera/galera-log-explainer/input"
+	"github.com/ylacancellera/galera-log-explainer/regex"
 	"github.com/ylacancellera/galera-log-explainer/types"
 	"github.com/ylacancellera/galera-log-explainer/utils"
 )
@@ -36,19 +38,33 @@ var CLI struct {
 	Until            *time.Time      `help:"Only list events before this date"`
 	Verbosity        types.Verbosity `type:"counter" short:"v" default:"1" help:"-v: Detailed (default), -vv: DebugMySQL (add every mysql info the tool used), -vvv: Debug (internal tool debug)"`
 	PxcOperator      bool            `default:"false" help:"Analyze logs from Percona PXC operator. Off by default because it negatively impacts performance for non-k8s setups"`
+	RegexFile        string          `type:"existingfile" help:"YAML file declaring additional regexes, see README"`
 	ExcludeRegexes   []string        `help:"Remove regexes from analysis. List regexes using 'galera-log-explainer regex-list'"`
 	MergeByDirectory bool            `help:"Instead of relying on identification, merge contexts and columns by base directory. Very useful when dealing with many small logs organized per directories."`
+	Jobs             int             `default:"0" help:"Number of files to extract at the same time. 0 will use one per CPU"`
+	InputFormat      string          `default:"auto" enum:"auto,error-log,journald-json" help:"Format of the files. 'journald-json' is the output of 'journalctl -o json', 'auto' detects it from the content"`
+	SyslogYear       int             `help:"Year of syslog and journald dates, as they do not have any. By default, it is guessed from each file modification time"`
+	Timezone         string          `default:"UTC" help:"Timezone of dates written without any, like MySQL 5.5, 5.6, MariaDB and syslog ones. 'Local' is the system timezone"`
+	Tz               []string        `sep:"none" placeholder:"GLOB=TIMEZONE" help:"Timezone of dates written without any, for the paths matching the glob, eg: --tz 'node3/*.log=Europe/Paris'. Can be repeated, the first match wins"`
+	DisplayTimezone  string          `help:"Display every dates in this timezone, instead of how they were written"`
+	DisplayLayout    string          `help:"Display every dates with this Go layout, eg: '2006-01-02 15:04:05'. Defaults to RFC3339 with microseconds when --display-timezone is used"`
 
-	List      list       `cmd:""`
-	Whois     whois      `cmd:""`
-	Sed       sed        `cmd:""`
-	Ctx       ctx        `cmd:""`
-	RegexList regexList  `cmd:""`
-	Version   versioncmd `cmd:""`
-	Conflicts conflicts  `cmd:""`
+	List        list        `cmd:""`
+	Whois       whois       `cmd:""`
+	Sed         sed         `cmd:""`
+	Ctx         ctx         `cmd:""`
+	RegexList   regexList   `cmd:""`
+	Version     versioncmd  `cmd:""`
+	Conflicts   conflicts   `cmd:""`
+	Views       views       `cmd:""`
+	Skew        skew        `cmd:""`
+	Discover    discover    `cmd:""`
+	ExplainLine explainLine `cmd:""`
 
-	GrepCmd  string `help:"'grep' command path. Could need to be set to 'ggrep' for darwin systems" default:"grep"`
-	GrepArgs string `help:"'grep' arguments. perl regexp (-P) is necessary. -o will break the tool" default:"-P"`
+	CacheDir string `help:"Keep what was found in each file in this directory, so that the next commands only search new or modified files. Only used with --engine=native"`
+	Engine   string `help:"How logs are searched. 'native' does not need any external binary, 'grep' will use --grep-cmd" default:"native" enum:"native,grep"`
+	GrepCmd  string `help:"'grep' command path, only used with --engine=grep. Could need to be set to 'ggrep' for darwin systems" default:"grep"`
+	GrepArgs string `help:"'grep' arguments, only used with --engine=grep. perl regexp (-P) is necessary. -o will break the tool" default:"-P"`
 }
 
 func main() {
@@ -66,6 +82,13 @@ func main() {
 	}
 
 	utils.SkipColor = CLI.NoColor
+	ctx.FatalIfErrorf(setupTimezones())
+	if CLI.RegexFile != "" {
+		ctx.FatalIfErrorf(regex.LoadRegexFile(CLI.RegexFile))
+	}
 	err := ctx.Run()
+	if cerr := input.Cleanup(); cerr != nil {
+		log.Warn().Err(cerr).Msg("failed to remove extracted archives")
+	}
 	ctx.FatalIfErrorf(err)
 }
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/input"
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
+)
+
+const (
+	maxLineLength = 64 * 1024 * 1024
+
+	// records are capped, to avoid accumulating huge chunks of logs that have no dates at all
+	maxRecordLines = 1000
+)
+
+// nativeFilter is the in-process equivalent of the grep argument built by prepareGrepArgument
+type nativeFilter struct {
+	regexes  *regex.Prefilter
+	operator *regex.Prefilter
+	records  *regex.Prefilter // nil when no MultiLine regexes are used
+}
+
+func prepareNativeFilter(regexes types.RegexMap) *nativeFilter {
+	lines, records := regexes.SplitMultiLine()
+	filter := &nativeFilter{regexes: regex.NewPrefilter(lines.Regexes())}
+	if len(records) > 0 {
+		filter.records = regex.NewPrefilter(records.Regexes())
+	}
+
+	if CLI.PxcOperator {
+		// same special case as in prepareGrepArgument: operator regexes are searched from the start of the line
+		// and every other regexes have to be found in k8s json logs
+		anchored := []*regexp.Regexp{}
+		for _, re := range regex.PXCOperatorMap.Regexes() {
+			anchored = append(anchored, regexp.MustCompile("^(?:"+re.String()+")"))
+		}
+		filter.operator = regex.NewPrefilter(anchored)
+		regexes.Merge(regex.PXCOperatorMap)
+	}
+	// --since is not handled here: lookaheads used by NoDatesRegex are not supported by golang regexes
+	// dates are filtered afterward in iterateOnGrepResults anyway
+	return filter
+}
+
+// nativeRegexes removes the line regexes made useless by MultiLine ones
+// They are kept for operator logs: k8s can interleave lines from other files in the middle of a record
+func nativeRegexes(regexes types.RegexMap) types.RegexMap {
+	if CLI.PxcOperator {
+		return regexes
+	}
+	return regexes.WithoutSuperseded()
+}
+
+func (f *nativeFilter) MatchString(line string) bool {
+	if f.operator != nil {
+		if f.operator.MatchString(line) {
+			return true
+		}
+		if !strings.HasPrefix(line, `{"log":"`) {
+			return false
+		}
+	}
+	return f.regexes.MatchString(line)
+}
+
+// execNativeAndIterate reads the file in-process, sending every line matching the filter
+// It is the default engine: it does not depend on any external binary
+func execNativeAndIterate(path string, filter *nativeFilter, stdout chan<- searchResult) error {
+
+	defer close(stdout)
+
+	f, err := input.OpenFormat(path, CLI.InputFormat)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", path)
+	}
+	defer f.Close()
+
+	emit := func(result searchResult) { stdout <- result }
+	assembler := &recordAssembler{filter: filter}
+
+	s := bufio.NewScanner(f)
+	// some lines are very long, especially k8s logs containing state dumps
+	s.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+	for s.Scan() {
+		assembler.feed(s.Text(), emit)
+	}
+	assembler.flush(emit)
+	return errors.Wrapf(s.Err(), "failed to read %s", path)
+}
+
+// recordAssembler groups a dated line with every following lines that have no date
+// eg: "View:" blocks, evs state dumps, backtraces
+// Lines matching the filter are still sent individually, as soon as they are read
+type recordAssembler struct {
+	filter *nativeFilter
+	lines  []string
+}
+
+// feed returns true when the line starts a new record. Without MultiLine regexes, every line is its own record
+func (a *recordAssembler) feed(line string, emit func(searchResult)) bool {
+	started := true
+	if a.filter.records != nil {
+		// syslog and journald lines all have a date: only the message tells if a new record starts
+		content := line
+		if message, ok := regex.SyslogMessage(line); ok {
+			content = message
+		}
+		_, _, started = regex.SearchDateFromLog(content)
+		if started {
+			a.flush(emit)
+			content = line
+		}
+		if CLI.PxcOperator {
+			content, _ = regex.K8sMessage(content)
+		}
+		if len(a.lines) < maxRecordLines {
+			a.lines = append(a.lines, content)
+		}
+	}
+	if a.filter.MatchString(line) {
+		emit(searchResult{text: line})
+	}
+	return started
+}
+
+// flush sends the current record if it is needed, and starts a new one
+func (a *recordAssembler) flush(emit func(searchResult)) {
+	if len(a.lines) == 0 {
+		return
+	}
+	record := strings.Join(a.lines, "\n")
+	a.lines = a.lines[:0]
+	if a.filter.records.MatchString(record) {
+		emit(searchResult{text: record, record: true})
+	}
+}
@@ -2,6 +2,8 @@ package regex
 
 import (
 	"regexp"
+	"strconv"
+	"time"
 
 	"github.com/ylacancellera/galera-log-explainer/types"
 	"github.com/ylacancellera/galera-log-explainer/utils"
@@ -20,8 +22,12 @@ var ApplicativeMap = types.RegexMap{
 			ctx.Desynced = true
 
 			node := submatches[groupNodeName]
+			rsu := rsuDesync(ctx)
 			return ctx, func(ctx types.LogCtx) string {
 				if utils.SliceContains(ctx.OwnNames, node) {
+					if rsu {
+						return utils.Paint(utils.YellowText, "desyncs itself from group") + " for RSU"
+					}
 					return utils.Paint(utils.YellowText, "desyncs itself from group")
 				}
 				return node + utils.Paint(utils.YellowText, " desyncs itself from group")
@@ -172,6 +178,272 @@ var ApplicativeMap = types.RegexMap{
 		},
 		Verbosity: types.DebugMySQL,
 	},
+
+	// Galera 3 does not vote, the applier error is all we get. Galera 4 logs it before its vote
+	// 2023-01-01T01:01:01.000000Z 10 [ERROR] Slave SQL: Could not execute Write_rows event on table test.t1; Can't find record in 't1', Error_code: 1032; handler error HA_ERR_KEY_NOT_FOUND; the event's master log FIRST, end_log_pos 164, Error_code: 1032
+	"RegexApplierError": &types.LogRegex{
+		Regex:         regexp.MustCompile("Could not execute [A-Za-z_]+ event on table"),
+		InternalRegex: regexp.MustCompile("Could not execute (?P<event>[A-Za-z_]+) event on table (?P<table>[^;]+); (?P<error>[^;]*?),? Error_code: (MY-)?0*(?P<errorcode>[0-9]+);( handler error (?P<handlererror>[A-Z_]+))?"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			node := types.Identifier(ctx)
+			table := submatches["table"]
+			vote := types.ConflictVote{Error: submatches["error"], ErrorCode: submatches["errorcode"]}
+
+			ctx.Conflicts = append(ctx.Conflicts, &types.Conflict{
+				InitiatedBy: []string{node},
+				Table:       table,
+				VotePerNode: map[string]types.ConflictVote{node: vote},
+			})
+
+			msg := utils.Paint(utils.RedText, "could not apply "+submatches["event"]) + " on " + table + ": error " + vote.ErrorCode
+			if submatches["handlererror"] != "" {
+				msg += "(" + submatches["handlererror"] + ")"
+			}
+			return ctx, types.SimpleDisplayer(msg)
+		},
+	},
+
+	// 2023-01-01T01:01:01.000000Z 10 [ERROR] WSREP: Failed to apply trx: source: 8bd1a1a4-ee8b-11ed-8aee-f7542ad73e53 version: 3 local: 0 state: APPLYING flags: 1 conn_id: 3 trx_id: 1234 seqnos (l: 5, g: 1234, s: 1233, d: 1233, ts: 12345)
+	// 2023-01-01T01:01:01.000000Z 10 [ERROR] WSREP: Failed to apply trx 1234 4 times
+	"RegexFailedToApplyTrx": &types.LogRegex{
+		Regex:         regexp.MustCompile("Failed to apply trx"),
+		InternalRegex: regexp.MustCompile("Failed to apply trx(:.*seqnos \\(l: -?[0-9]+, g: | )" + regexSeqno),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			seqno := submatches[groupSeqno]
+
+			// the seqno is logged twice, only the first time is shown
+			if ctx.Conflicts.ConflictWithSeqno(seqno) != nil {
+				return ctx, nil
+			}
+			c := ctx.Conflicts.Pending()
+			if c == nil {
+				node := types.Identifier(ctx)
+				c = &types.Conflict{InitiatedBy: []string{node}, VotePerNode: map[string]types.ConflictVote{node: {}}}
+				ctx.Conflicts = append(ctx.Conflicts, c)
+			}
+			c.Seqno = seqno
+
+			msg := utils.Paint(utils.RedText, "failed to apply trx") + "(seqno:" + seqno + ")"
+			if c.Table != "" {
+				msg += " on " + c.Table
+			}
+			return ctx, types.SimpleDisplayer(msg)
+		},
+	},
+
+	// 2023-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] Flow-control interval: [100, 100]
+	"RegexFlowControlInterval": &types.LogRegex{
+		Regex:         regexp.MustCompile("Flow-control interval:"),
+		InternalRegex: regexp.MustCompile("Flow-control interval: (?P<interval>\\[[0-9]+, [0-9]+\\])"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			ctx.FlowControl.Interval = submatches["interval"]
+			return ctx, types.SimpleDisplayer("flow control interval: " + submatches["interval"])
+		},
+		Verbosity: types.Detailed,
+	},
+
+	// this node is the one slowing down the cluster
+	// 2023-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] SENDING FC_STOP (local seqno: 1234, fc_offset: 0, cond: 1)
+	"RegexFlowControlStop": &types.LogRegex{
+		Regex:         regexp.MustCompile("SENDING FC_STOP"),
+		InternalRegex: regexp.MustCompile("SENDING FC_STOP \\(local seqno: " + regexSeqno),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			ctx.FlowControl.Pause(DateFromLog(ctx, log))
+			return ctx, types.SimpleDisplayer(utils.Paint(utils.YellowText, "flow control: asked the cluster to pause writes") + "(seqno:" + submatches[groupSeqno] + ")")
+		},
+		Verbosity: types.Detailed,
+	},
+
+	// 2023-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] SENDING FC_CONT (local seqno: 1240, fc_offset: 0, cond: 1)
+	"RegexFlowControlCont": &types.LogRegex{
+		Regex:         regexp.MustCompile("SENDING FC_CONT"),
+		InternalRegex: regexp.MustCompile("SENDING FC_CONT \\(local seqno: " + regexSeqno),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			pause, ok := ctx.FlowControl.Resume(DateFromLog(ctx, log))
+			if !ok {
+				return ctx, types.SimpleDisplayer("flow control: asked the cluster to resume writes(seqno:" + submatches[groupSeqno] + ")")
+			}
+			msg := utils.Paint(utils.YellowText, "flow control: cluster writes were paused by this node")
+			if d, ok := pause.Duration(); ok {
+				msg += " for " + d.Round(time.Millisecond).String()
+			}
+			return ctx, types.SimpleDisplayer(msg)
+		},
+	},
+
+	// 2023-04-16T19:35:06.875877Z 0 [Warning] WSREP: FLOW message from member -12921743687968 in non-primary configuration. Ignored.
+	"RegexFlowControlNonPrimary": &types.LogRegex{
+		Regex:         regexp.MustCompile("FLOW message from member"),
+		InternalRegex: regexp.MustCompile("FLOW message from member (?P<member>-?[0-9]+) in non-primary configuration"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			ctx.FlowControl.Ignored++
+			return ctx, types.SimpleDisplayer("flow control from member " + submatches["member"] + " ignored, " + utils.Paint(utils.YellowText, "non-primary"))
+		},
+		Verbosity: types.Detailed,
+	},
+
+	// 2023-04-16T19:35:06.875877Z 0 [Warning] [MY-000000] [Galera] Action message in non-primary configuration from member 0
+	"RegexActionNonPrimary": &types.LogRegex{
+		Regex:         regexp.MustCompile("Action message in non-primary configuration"),
+		InternalRegex: regexp.MustCompile("from member (?P<member>-?[0-9]+)"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			return ctx, types.SimpleDisplayer("action from member " + submatches["member"] + " ignored, " + utils.Paint(utils.YellowText, "non-primary"))
+		},
+		Verbosity: types.Detailed,
+	},
+
+	// only logged with wsrep_log_conflicts. The first transaction lost against the second one
+	// 2023-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] trx conflict for key (1,FLAT8)258634b1 d4b5f8f5: source: fb9d6310-ee8b-11ed-8aee-f7542ad73e53 version: 5 local: 1 flags: 1 conn_id: 48 trx_id: 2696 tstamp: 1683653959142522853; state: CERTIFYING:0 seqnos (l: 16, g: 21, s: 19, d: 20) <--X--> source: 0b4e8b3b-ee8b-11ed-a1d2-2f2c1d1c9a7e version: 5 local: 0 flags: 1 conn_id: 12 trx_id: 2690 tstamp: 1683653959142522000; state: COMMITTED:0 seqnos (l: 15, g: 20, s: 18, d: 19)
+	"RegexCertificationFailure": &types.LogRegex{
+		Regex:         regexp.MustCompile("trx conflict for key"),
+		InternalRegex: regexp.MustCompile("trx conflict for key .*?source: " + regexUUID),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			trxs := trxSeparatorRegex.Split(log, 2)
+			c := parseTrx(types.CertificationFailure, trxs[0])
+			c.Date = DateFromLog(ctx, log)
+			if len(trxs) > 1 {
+				c.WinnerSource = parseTrx(types.CertificationFailure, trxs[1]).Source
+			}
+			ctx.CertificationConflicts = ctx.CertificationConflicts.Add(c)
+
+			return ctx, func(ctx types.LogCtx) string {
+				msg := utils.Paint(utils.YellowText, "certification failed")
+				if c.Seqno != "" {
+					msg += "(seqno:" + c.Seqno + ")"
+				}
+				msg += ": trx from " + types.DisplayHashSimplestForm(ctx, utils.UUIDToShortUUID(c.Source))
+				if c.WinnerSource != "" {
+					msg += " lost against " + types.DisplayHashSimplestForm(ctx, utils.UUIDToShortUUID(c.WinnerSource))
+				}
+				return msg
+			}
+		},
+	},
+
+	// 2023-06-13  1:15:27 35 [Note] WSREP: cluster conflict due to high priority abort for threads:
+	"RegexBFAbort": &types.LogRegex{
+		Regex: regexp.MustCompile("cluster conflict due to high priority abort"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			ctx.CertificationConflicts = ctx.CertificationConflicts.Add(types.CertificationConflict{Kind: types.BFAbort, Date: DateFromLog(ctx, log)})
+			return ctx, types.SimpleDisplayer(utils.Paint(utils.YellowText, "local trx brute force aborted") + " by a replicated one")
+		},
+	},
+
+	// 2023-06-13  1:15:27 35 [Note] WSREP: MDL BF-BF conflict
+	"RegexMDLBFBFConflict": &types.LogRegex{
+		Regex: regexp.MustCompile("MDL BF-BF conflict"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			ctx.CertificationConflicts = ctx.CertificationConflicts.Add(types.CertificationConflict{Kind: types.MDLBFBFConflict, Date: DateFromLog(ctx, log)})
+			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "MDL BF-BF conflict") + " between replicated trx")
+		},
+	},
+
+	// 2023-05-09T17:39:19.955040Z 51 [Warning] [MY-000000] [Galera] failed to replay trx: source: fb9d6310-ee8b-11ed-8aee-f7542ad73e53 version: 5 local: 1 flags: 1 conn_id: 48 trx_id: 2696 tstamp: 1683653959142522853; state: EXECUTING:0->REPLICATING:782->CERTIFYING:3509->APPLYING:3748->COMMITTING:1343->COMMITTED:-1
+	"RegexReplayFailed": &types.LogRegex{
+		Regex:         regexp.MustCompile("failed to replay trx"),
+		InternalRegex: regexp.MustCompile("failed to replay trx: source: " + regexUUID),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			return replayFailure(ctx, log, "failed to replay trx", utils.YellowText)
+		},
+	},
+
+	// 2023-05-09T17:39:19.955085Z 51 [Warning] [MY-000000] [Galera] Invalid state in replay for trx source: fb9d6310-ee8b-11ed-8aee-f7542ad73e53 version: 5 local: 1 flags: 1 conn_id: 48 trx_id: 2696 tstamp: 1683653959142522853; state: EXECUTING:0->REPLICATING:782->CERTIFYING:3509->APPLYING:3748->COMMITTING:1343->COMMITTED:-1 (FATAL)
+	"RegexReplayInvalidState": &types.LogRegex{
+		Regex:         regexp.MustCompile("Invalid state in replay"),
+		InternalRegex: regexp.MustCompile("Invalid state in replay for trx source: " + regexUUID),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			return replayFailure(ctx, log, "invalid state in replay", utils.RedText)
+		},
+	},
+
+	// 2023-01-01T01:01:01.000000Z 48 [ERROR] WSREP: transaction size limit (2147483647) exceeded: 2147483648
+	// 2023-01-01T01:01:01.000000Z 48 [ERROR] WSREP: transaction size exceeds wsrep_max_ws_size
+	"RegexTrxSizeLimitExceeded": &types.LogRegex{
+		Regex:         regexp.MustCompile("transaction size (limit \\([0-9]+\\) exceeded|exceeds wsrep_max_ws_size)"),
+		InternalRegex: regexp.MustCompile("transaction size (limit \\((?P<limit>[0-9]+)\\) exceeded: (?P<size>[0-9]+)|exceeds)"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			return writeSetTooLarge(ctx, log, submatches["size"], submatches["limit"])
+		},
+	},
+
+	// 2023-01-01T01:01:01.000000Z 48 [Warning] [MY-000000] [Galera] Maximum writeset size exceeded by 1: 2147483649
+	// 2023-01-01T01:01:01.000000Z 48 [Warning] [MY-000000] [Galera] Writeset size 2147483649 exceeds the limit
+	"RegexWriteSetSizeExceeded": &types.LogRegex{
+		Regex:         regexp.MustCompile("(Maximum writeset size exceeded by|Writeset size [0-9]+ exceeds)"),
+		InternalRegex: regexp.MustCompile("(exceeded by [0-9]+: |Writeset size )(?P<size>[0-9]+)"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			return writeSetTooLarge(ctx, log, submatches["size"], "")
+		},
+	},
+
+	// 2023-01-01T01:01:01.000000Z 14 [ERROR] [MY-000000] [WSREP] Failed to apply SR fragment for trx_id: 2696, seqno: 1234
+	"RegexSRFragmentApplyFailed": &types.LogRegex{
+		Regex: regexp.MustCompile("([Ff]ailed to apply (SR|streaming replication) fragment|SR fragment apply failed)"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			trx := types.LargeTransaction{Kind: types.SRFragmentApplyFailure, Date: DateFromLog(ctx, log), ConnID: connIDFromLog(log)}
+			if m := srTrxIDRegex.FindStringSubmatch(log); m != nil {
+				trx.TrxID = m[1]
+			}
+			ctx.LargeTransactions.Add(trx)
+
+			msg := utils.Paint(utils.RedText, "failed to apply streaming replication fragment")
+			if trx.TrxID != "" {
+				msg += "(trx_id:" + trx.TrxID + ")"
+			}
+			return ctx, types.SimpleDisplayer(msg)
+		},
+	},
+}
+
+var (
+	// "<--X-->" in Galera 3, "<--->" in some Galera 4 versions
+	trxSeparatorRegex = regexp.MustCompile("<--X?-->")
+	trxSourceRegex    = regexp.MustCompile("source: " + regexUUID)
+	trxIDsRegex       = regexp.MustCompile("conn_id: (?P<connid>-?[0-9]+) trx_id: (?P<trxid>-?[0-9]+)")
+	trxSeqnoRegex     = regexp.MustCompile("seqnos \\(l: -?[0-9]+, g: " + regexSeqno)
+	srTrxIDRegex      = regexp.MustCompile("trx(?:_id)?:? (?P<trxid>[0-9]+)")
+)
+
+// writeSetTooLarge keeps the connection id, it is what ties the write set to a batch job
+func writeSetTooLarge(ctx types.LogCtx, log, size, limit string) (types.LogCtx, types.LogDisplayer) {
+	trx := types.LargeTransaction{Kind: types.WriteSetTooLarge, Date: DateFromLog(ctx, log), ConnID: connIDFromLog(log)}
+	trx.Size, _ = strconv.ParseInt(size, 10, 64)
+	ctx.LargeTransactions.Add(trx)
+
+	msg := utils.Paint(utils.YellowText, "write set too large")
+	if trx.Size > 0 {
+		msg += ": " + utils.HumanBytes(trx.Size)
+		if l, err := strconv.ParseInt(limit, 10, 64); err == nil {
+			msg += " (limit " + utils.HumanBytes(l) + ")"
+		}
+	}
+	if trx.ConnID != "" {
+		msg += ", conn_id:" + trx.ConnID
+	}
+	return ctx, types.SimpleDisplayer(msg)
+}
+
+// parseTrx gets what identifies a transaction as printed by galera
+func parseTrx(kind types.CertificationConflictKind, s string) types.CertificationConflict {
+	c := types.CertificationConflict{Kind: kind}
+	if m := trxSourceRegex.FindStringSubmatch(s); m != nil {
+		c.Source = m[1]
+	}
+	if m := trxIDsRegex.FindStringSubmatch(s); m != nil {
+		c.ConnID, c.TrxID = m[1], m[2]
+	}
+	if m := trxSeqnoRegex.FindStringSubmatch(s); m != nil {
+		c.Seqno = m[1]
+	}
+	return c
+}
+
+// failures to replay are logged twice, they are counted once
+func replayFailure(ctx types.LogCtx, log, msg string, color utils.Color) (types.LogCtx, types.LogDisplayer) {
+	c := parseTrx(types.ReplayFailure, log)
+	c.Date = DateFromLog(ctx, log)
+	ctx.CertificationConflicts = ctx.CertificationConflicts.Add(c)
+	return ctx, types.SimpleDisplayer(utils.Paint(color, msg) + "(conn_id:" + c.ConnID + ", trx_id:" + c.TrxID + ")")
 }
 
 func voteResponse(vote types.ConflictVote, conflict types.Conflict) string {
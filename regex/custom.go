@@ -0,0 +1,250 @@
+package regex
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+	"gopkg.in/yaml.v2"
+)
+
+// CustomRegex is a regex declared by users in a --regex-file, instead of in one of the maps
+/*
+regexes:
+  - key: RegexMyAppPaused
+    type: events
+    regex: "paused by"
+    internalRegex: "paused by (?P<ip>[0-9.]+)"
+    verbosity: detailed
+    display: '{{ paint "yellow" "paused by" }} {{ node .ip }}'
+    effects:
+      state: DESYNCED
+*/
+type CustomRegex struct {
+	Key           string        `yaml:"key"`
+	Type          string        `yaml:"type"`
+	Regex         string        `yaml:"regex"`                   // prefilter, as simple as possible
+	InternalRegex string        `yaml:"internalRegex,omitempty"` // named groups are given to templates
+	Verbosity     string        `yaml:"verbosity,omitempty"`     // info, detailed (default), debugmysql, debug
+	MultiLine     bool          `yaml:"multiLine,omitempty"`
+	Order         int           `yaml:"order,omitempty"`    // lines matched by several regexes are given to the lowest order first
+	Terminal      bool          `yaml:"terminal,omitempty"` // the next regexes are not applied to lines it matched
+	Display       string        `yaml:"display,omitempty"`  // template, nothing is displayed when empty
+	Effects       CustomEffects `yaml:"effects,omitempty"`
+}
+
+// CustomEffects are what the regex teaches about the node, every field is a template
+type CustomEffects struct {
+	State   string `yaml:"state,omitempty"` // one of the wsrep states, others are ignored
+	OwnIP   string `yaml:"ownIP,omitempty"`
+	OwnName string `yaml:"ownName,omitempty"`
+	OwnHash string `yaml:"ownHash,omitempty"`
+	SSTType string `yaml:"sstType,omitempty"`
+}
+
+type customRegexFile struct {
+	Regexes []CustomRegex `yaml:"regexes"`
+}
+
+var verbosities = map[string]types.Verbosity{
+	"info":       types.Info,
+	"detailed":   types.Detailed,
+	"debugmysql": types.DebugMySQL,
+	"debug":      types.Debug,
+}
+
+// mapForType gives where custom regexes are added, so that they are selected like the built-in ones
+func mapForType(t string) (types.RegexMap, types.RegexType, bool) {
+	switch types.RegexType(t) {
+	case types.EventsRegexType:
+		return EventsMap, types.EventsRegexType, true
+	case types.SSTRegexType:
+		return SSTMap, types.SSTRegexType, true
+	case types.ViewsRegexType:
+		return ViewsMap, types.ViewsRegexType, true
+	case types.IdentRegexType:
+		return IdentsMap, types.IdentRegexType, true
+	case types.StatesRegexType:
+		return StatesMap, types.StatesRegexType, true
+	case types.ApplicativeRegexType:
+		return ApplicativeMap, types.ApplicativeRegexType, true
+	case types.PXCOperatorRegexType:
+		return PXCOperatorMap, types.PXCOperatorRegexType, true
+	case types.ResourcesRegexType:
+		return ResourcesMap, types.ResourcesRegexType, true
+	case types.NetworkRegexType:
+		return NetworkMap, types.NetworkRegexType, true
+	case types.DDLRegexType:
+		return DDLMap, types.DDLRegexType, true
+	}
+	return nil, "", false
+}
+
+// LoadRegexFile adds every regexes of the file to the map of their type
+func LoadRegexFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to read regex file")
+	}
+	file := customRegexFile{}
+	if err := yaml.UnmarshalStrict(content, &file); err != nil {
+		return errors.Wrapf(err, "invalid regex file %s", path)
+	}
+
+	existing := AllRegexes().Merge(PXCOperatorMap)
+	for _, custom := range file.Regexes {
+		if _, ok := existing[custom.Key]; ok {
+			return errors.Errorf("regex %s from %s already exists", custom.Key, path)
+		}
+		m, _, ok := mapForType(custom.Type)
+		if !ok {
+			return errors.Errorf("regex %s has an invalid type %q", custom.Key, custom.Type)
+		}
+		logRegex, err := custom.toLogRegex()
+		if err != nil {
+			return errors.Wrapf(err, "invalid regex %s", custom.Key)
+		}
+		m[custom.Key] = logRegex
+		existing[custom.Key] = logRegex
+	}
+	return nil
+}
+
+// MarshalRegexFile gives the content of a --regex-file declaring the regexes
+func MarshalRegexFile(regexes []CustomRegex) ([]byte, error) {
+	out, err := yaml.Marshal(customRegexFile{Regexes: regexes})
+	return out, errors.Wrap(err, "failed to serialize regexes")
+}
+
+func (c CustomRegex) toLogRegex() (*types.LogRegex, error) {
+	if c.Key == "" {
+		return nil, errors.New("key is missing")
+	}
+	if c.Regex == "" {
+		return nil, errors.New("regex is missing")
+	}
+	_, regexType, _ := mapForType(c.Type)
+
+	verbosity := types.Detailed
+	if c.Verbosity != "" {
+		v, ok := verbosities[strings.ToLower(c.Verbosity)]
+		if !ok {
+			return nil, errors.Errorf("invalid verbosity %q", c.Verbosity)
+		}
+		verbosity = v
+	}
+
+	re, err := regexp.Compile(c.Regex)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid regex")
+	}
+	var internal *regexp.Regexp
+	if c.InternalRegex != "" {
+		internal, err = regexp.Compile(c.InternalRegex)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid internalRegex")
+		}
+	}
+
+	display, err := parseCustomTemplate("display", c.Display)
+	if err != nil {
+		return nil, err
+	}
+	effects := map[string]*template.Template{}
+	for name, text := range map[string]string{"state": c.Effects.State, "ownIP": c.Effects.OwnIP, "ownName": c.Effects.OwnName, "ownHash": c.Effects.OwnHash, "sstType": c.Effects.SSTType} {
+		effects[name], err = parseCustomTemplate(name, text)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &types.LogRegex{
+		Regex:         re,
+		InternalRegex: internal,
+		Type:          regexType,
+		Verbosity:     verbosity,
+		MultiLine:     c.MultiLine,
+		Order:         c.Order,
+		Terminal:      c.Terminal,
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+
+			if s, _ := execCustomTemplate(effects["state"], submatches, ctx); s != "" {
+				ctx.SetState(s)
+			}
+			if s, _ := execCustomTemplate(effects["ownIP"], submatches, ctx); s != "" {
+				ctx.AddOwnIP(s)
+			}
+			if s, _ := execCustomTemplate(effects["ownName"], submatches, ctx); s != "" {
+				ctx.AddOwnName(s)
+			}
+			if s, _ := execCustomTemplate(effects["ownHash"], submatches, ctx); s != "" {
+				ctx.AddOwnHash(s)
+			}
+			if s, _ := execCustomTemplate(effects["sstType"], submatches, ctx); s != "" {
+				ctx.SST.Type = s
+			}
+
+			if display == nil {
+				return ctx, nil
+			}
+			return ctx, func(ctx types.LogCtx) string {
+				s, err := execCustomTemplate(display, submatches, ctx)
+				if err != nil {
+					return utils.Paint(utils.RedText, "template error: "+err.Error())
+				}
+				return s
+			}
+		},
+	}, nil
+}
+
+// customTemplateFuncs are the helpers available in templates. They depend on the context the template is executed with
+func customTemplateFuncs(ctx types.LogCtx) template.FuncMap {
+	return template.FuncMap{
+		"node":      func(ip string) string { return types.DisplayNodeSimplestForm(ctx, ip) },
+		"hash":      func(hash string) string { return types.DisplayHashSimplestForm(ctx, hash) },
+		"shortName": utils.ShortNodeName,
+		"shortUUID": utils.UUIDToShortUUID,
+		"state":     func(s string) string { return utils.PaintForState(s, s) },
+		"paint": func(color, s string) string {
+			switch color {
+			case "red":
+				return utils.Paint(utils.RedText, s)
+			case "green":
+				return utils.Paint(utils.GreenText, s)
+			case "yellow":
+				return utils.Paint(utils.YellowText, s)
+			case "blue":
+				return utils.Paint(utils.BlueText, s)
+			}
+			return s
+		},
+	}
+}
+
+func parseCustomTemplate(name, text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	t, err := template.New(name).Funcs(customTemplateFuncs(types.LogCtx{})).Option("missingkey=zero").Parse(text)
+	return t, errors.Wrapf(err, "invalid %s template", name)
+}
+
+func execCustomTemplate(t *template.Template, submatches map[string]string, ctx types.LogCtx) (string, error) {
+	if t == nil {
+		return "", nil
+	}
+	// functions have to be bound to the context, on a copy as displayers can be called concurrently
+	t, err := t.Clone()
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	err = t.Funcs(customTemplateFuncs(ctx)).Execute(buf, submatches)
+	return buf.String(), err
+}
@@ -0,0 +1,91 @@
+package regex
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+func TestLoadRegexFile(t *testing.T) {
+	utils.SkipColor = true
+	path := filepath.Join(t.TempDir(), "custom.yaml")
+	content := `
+regexes:
+  - key: RegexTestCustomPause
+    type: states
+    regex: "app paused"
+    internalRegex: "app paused by (?P<ip>[0-9.]+), (?P<name>[a-z0-9]+)"
+    verbosity: info
+    display: '{{ paint "yellow" "paused by" }} {{ node .ip }}'
+    effects:
+      state: DESYNCED
+      ownName: "{{ .name }}"
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadRegexFile(path); err != nil {
+		t.Fatal(err)
+	}
+	defer delete(StatesMap, "RegexTestCustomPause")
+
+	regex, ok := StatesMap["RegexTestCustomPause"]
+	if !ok {
+		t.Fatal("custom regex was not added to its map")
+	}
+	if regex.Type != types.StatesRegexType || regex.Verbosity != types.Info {
+		t.Errorf("unexpected type or verbosity: %s, %d", regex.Type, regex.Verbosity)
+	}
+
+	log := "2001-01-01T01:01:01.000000Z 0 [Note] WSREP: app paused by 172.17.0.2, node1"
+	if !regex.Regex.MatchString(log) {
+		t.Fatal("regex did not match")
+	}
+	ctx := types.NewLogCtx()
+	ctx.FileType = "error.log"
+	ctx.IPToNodeName["172.17.0.2"] = "node2"
+	ctx, displayer := regex.Handle(ctx, log)
+	if ctx.State() != "DESYNCED" {
+		t.Errorf("expected state DESYNCED, got %s", ctx.State())
+	}
+	if len(ctx.OwnNames) != 1 || ctx.OwnNames[0] != "node1" {
+		t.Errorf("expected own name node1, got %v", ctx.OwnNames)
+	}
+	if out := displayer(ctx); out != "paused by node2" {
+		t.Errorf("expected 'paused by node2', got %s", out)
+	}
+
+	// the same key can't be declared twice
+	if err := LoadRegexFile(path); err == nil {
+		t.Error("expected an error for an already existing regex")
+	}
+}
+
+func TestLoadRegexFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "unknown field", content: "regexes:\n  - key: RegexTestA\n    type: events\n    regex: a\n    unknown: b\n"},
+		{name: "invalid type", content: "regexes:\n  - key: RegexTestA\n    type: nothing\n    regex: a\n"},
+		{name: "built-in key", content: "regexes:\n  - key: RegexShift\n    type: states\n    regex: a\n"},
+		{name: "missing regex", content: "regexes:\n  - key: RegexTestA\n    type: events\n"},
+		{name: "invalid regex", content: "regexes:\n  - key: RegexTestA\n    type: events\n    regex: \"(\"\n"},
+		{name: "invalid template", content: "regexes:\n  - key: RegexTestA\n    type: events\n    regex: a\n    display: \"{{ .a \"\n"},
+		{name: "invalid verbosity", content: "regexes:\n  - key: RegexTestA\n    type: events\n    regex: a\n    verbosity: loud\n"},
+	}
+
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), "custom.yaml")
+		if err := os.WriteFile(path, []byte(test.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := LoadRegexFile(path); err == nil {
+			t.Errorf("testname: %s, expected an error", test.name)
+		}
+		delete(EventsMap, "RegexTestA")
+	}
+}
@@ -2,10 +2,13 @@ package regex
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/ylacancellera/galera-log-explainer/types"
 	"github.com/ylacancellera/galera-log-explainer/utils"
 )
 
@@ -23,6 +26,20 @@ var DateLayouts = []string{
 	"2006/01/02 15:04:05",              // sometimes found in socat errors
 }
 
+// SyslogDateLayouts are the dates added by syslog and journald, in front of "host process[pid]: "
+// Order matters: the most precise layouts have to be tried first
+var SyslogDateLayouts = []string{
+	"2006-01-02T15:04:05.000000-0700", // journald short-iso-precise
+	"2006-01-02T15:04:05-0700",        // journald short-iso
+	"Jan _2 15:04:05.000000",          // journald short-precise
+	"Jan _2 15:04:05",                 // syslog, journald short
+}
+
+var syslogMonths = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+// after the date: "host mysqld[123]: "
+var syslogPrefixRegex = regexp.MustCompile(`^ +(?P<host>[^ \n]+) [^ :\[\n]+(\[[0-9]+\])?: `)
+
 // BetweenDateRegex generate a regex to filter mysql error log dates to just get
 // events between 2 dates
 // Currently limited to filter by day to produce "short" regexes. Finer events will be filtered later in code
@@ -78,32 +95,80 @@ func BetweenDateRegex(since *time.Time, skipLeadingCircumflex bool) string {
 
 		}
 	}
+
+	// syslog dates do not have years: the following 11 months are accepted, wrapping around the end of the year
+	// else logs would be lost after a new year. Older logs are filtered later in code, once the year is known
+	// days are either padded with a space (syslog) or a zero (journald)
+	s += separator + since.Format("Jan") + " (" + daysFrom(since.Day()) + ")"
+	nextMonths := append(append([]string{}, syslogMonths[since.Month():]...), syslogMonths[:since.Month()-1]...)
+	if len(nextMonths) > 0 {
+		s += separator + "(" + strings.Join(nextMonths, "|") + ") "
+	}
+
 	s += ")"
 	return "(" + s[1:]
 }
 
+// daysFrom generates a regex matching days of month from the given one, padded or not
+func daysFrom(day int) string {
+	switch {
+	case day < 10:
+		return "[ 0][" + strconv.Itoa(day) + "-9]|[12][0-9]|3[01]"
+	case day < 20:
+		return "1[" + strconv.Itoa(day%10) + "-9]|2[0-9]|3[01]"
+	case day < 30:
+		return "2[" + strconv.Itoa(day%10) + "-9]|3[01]"
+	default:
+		return "3[" + strconv.Itoa(day%10) + "-1]"
+	}
+}
+
 // basically capturing anything that does not have a date
 // needed, else we would miss some logs, like wsrep recovery
 func NoDatesRegex(skipLeadingCircumflex bool) string {
 	//return "((?![0-9]{4}-[0-9]{2}-[0-9]{2})|(?![0-9]{6}))"
+	noDate := "(?![0-9]{4}|(" + strings.Join(syslogMonths, "|") + ") )"
 	if skipLeadingCircumflex {
-		return "(?![0-9]{4})"
+		return noDate
 	}
-	return "^(?![0-9]{4})"
+	return "^" + noDate
 }
 
-/*
-SYSLOG_DATE="\(Jan\|Feb\|Mar\|Apr\|May\|Jun\|Jul\|Aug\|Sep\|Oct\|Nov\|Dec\) \( \|[0-9]\)[0-9] [0-9]\{2\}:[0-9]\{2\}:[0-9]\{2\}"
-REGEX_LOG_PREFIX="$REGEX_DATE \?[0-9]* "
-*/
-
 const k8sprefix = `{"log":"`
 
 func SearchDateFromLog(logline string) (time.Time, string, bool) {
-	if logline[:len(k8sprefix)] == k8sprefix {
-		logline = logline[len(k8sprefix):]
+	logline = strings.TrimPrefix(logline, k8sprefix)
+	if t, layout, ok := searchDate(logline, DateLayouts); ok {
+		return t, layout, true
+	}
+
+	// the message itself can still have a mysql date, it is more precise and it has a year
+	t, layout, ok := searchDate(logline, SyslogDateLayouts)
+	if !ok {
+		log.Debug().Str("log", logline).Msg("could not find date from log")
+		return time.Time{}, "", false
+	}
+	if message, ok := SyslogMessage(logline); ok {
+		if t, layout, ok := searchDate(message, DateLayouts); ok {
+			return t, layout, true
+		}
+	}
+	return t, layout, true
+}
+
+// DateFromLog finds the date of the line, completed by what the context knows: its timezone and year when the log did not give them
+// Handlers use it when they have to store the date of the event they are parsing
+func DateFromLog(ctx types.LogCtx, logline string) *types.Date {
+	t, layout, ok := SearchDateFromLog(logline)
+	if !ok {
+		return nil
 	}
-	for _, layout := range DateLayouts {
+	t = SetLocation(t, layout, ctx.Location)
+	return types.NewDate(CompleteYear(t, ctx.YearReference), layout)
+}
+
+func searchDate(logline string, layouts []string) (time.Time, string, bool) {
+	for _, layout := range layouts {
 		if len(logline) < len(layout) {
 			continue
 		}
@@ -112,6 +177,80 @@ func SearchDateFromLog(logline string) (time.Time, string, bool) {
 			return t, layout, true
 		}
 	}
-	log.Debug().Str("log", logline).Msg("could not find date from log")
 	return time.Time{}, "", false
 }
+
+// SyslogMessage removes "Jan  5 03:24:26 host mysqld[123]: " from the line
+// It returns false when the line does not come from syslog or journald
+func SyslogMessage(logline string) (string, bool) {
+	layout, loc := syslogPrefix(logline)
+	if loc == nil {
+		return logline, false
+	}
+	return logline[len(layout)+loc[1]:], true
+}
+
+// StripSyslogPrefix removes "host mysqld[123]: " so that regexes see the same messages as in mysql error logs
+// The syslog date is kept, unless the message already has its own mysql date
+func StripSyslogPrefix(logline string) string {
+	layout, loc := syslogPrefix(logline)
+	if loc == nil {
+		return logline
+	}
+	message := logline[len(layout)+loc[1]:]
+	if _, _, ok := searchDate(message, DateLayouts); ok {
+		return message
+	}
+	return logline[:len(layout)] + " " + message
+}
+
+// SyslogHost returns the host given in syslog and journald lines
+func SyslogHost(logline string) (string, bool) {
+	layout, loc := syslogPrefix(logline)
+	if loc == nil {
+		return "", false
+	}
+	// indexes are relative to the end of the date
+	hostStart, hostEnd := loc[2*syslogPrefixRegex.SubexpIndex("host")], loc[2*syslogPrefixRegex.SubexpIndex("host")+1]
+	return logline[len(layout)+hostStart : len(layout)+hostEnd], true
+}
+
+func syslogPrefix(logline string) (string, []int) {
+	for _, layout := range SyslogDateLayouts {
+		if len(logline) < len(layout) {
+			continue
+		}
+		if _, err := time.Parse(layout, logline[:len(layout)]); err != nil {
+			continue
+		}
+		return layout, syslogPrefixRegex.FindStringSubmatchIndex(logline[len(layout):])
+	}
+	return "", nil
+}
+
+// CompleteYear sets the year of dates that did not have any, like syslog ones
+// Logs can't have been written after the reference (usually the file modification time), so they are either from its year or the previous one
+func CompleteYear(t, reference time.Time) time.Time {
+	if t.Year() != 0 || reference.IsZero() {
+		return t
+	}
+	t = time.Date(reference.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if t.After(reference.Add(24 * time.Hour)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	return t
+}
+
+// SetLocation moves dates that did not have any timezone, they were parsed as UTC
+// 5.5, 5.6, MariaDB and syslog dates are written in the server local time
+func SetLocation(t time.Time, layout string, loc *time.Location) time.Time {
+	if loc == nil || hasTimezone(layout) {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// the "Z" from 5.7 dates is part of the layout, it is UTC
+func hasTimezone(layout string) bool {
+	return strings.Contains(layout, "Z") || strings.Contains(layout, "-07")
+}
@@ -1,8 +1,11 @@
 package regex
 
 import (
+	"regexp"
 	"testing"
 	"time"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
 )
 
 func TestDateAfter(t *testing.T) {
@@ -14,22 +17,32 @@ func TestDateAfter(t *testing.T) {
 		{
 			input:    "2006-01-02T15:04:05Z",
 			format:   time.RFC3339,
-			expected: "(^2006-01-02|^2006-01-0[3-9]|^2006-01-[1-9][0-9]|^2006-0[2-9]-[0-9][0-9]|^2006-[1-9][0-9]-[0-9][0-9]|^200[7-9]-[0-9][0-9]-[0-9][0-9]|^20[1-9][0-9]-[0-9][0-9]-[0-9][0-9]|^060102|^06010[3-9]|^0601[1-9][0-9]|^060[2-9][0-9][0-9]|^06[1-9][0-9][0-9][0-9]|^0[7-9][0-9][0-9][0-9][0-9]|^[1-9][0-9][0-9][0-9][0-9][0-9])",
+			expected: "(^2006-01-02|^2006-01-0[3-9]|^2006-01-[1-9][0-9]|^2006-0[2-9]-[0-9][0-9]|^2006-[1-9][0-9]-[0-9][0-9]|^200[7-9]-[0-9][0-9]-[0-9][0-9]|^20[1-9][0-9]-[0-9][0-9]-[0-9][0-9]|^060102|^06010[3-9]|^0601[1-9][0-9]|^060[2-9][0-9][0-9]|^06[1-9][0-9][0-9][0-9]|^0[7-9][0-9][0-9][0-9][0-9]|^[1-9][0-9][0-9][0-9][0-9][0-9]|^Jan ([ 0][2-9]|[12][0-9]|3[01])|^(Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) )",
 		},
 		{
 			input:    "2006-01-02",
 			format:   "2006-01-02",
-			expected: "(^2006-01-02|^2006-01-0[3-9]|^2006-01-[1-9][0-9]|^2006-0[2-9]-[0-9][0-9]|^2006-[1-9][0-9]-[0-9][0-9]|^200[7-9]-[0-9][0-9]-[0-9][0-9]|^20[1-9][0-9]-[0-9][0-9]-[0-9][0-9]|^060102|^06010[3-9]|^0601[1-9][0-9]|^060[2-9][0-9][0-9]|^06[1-9][0-9][0-9][0-9]|^0[7-9][0-9][0-9][0-9][0-9]|^[1-9][0-9][0-9][0-9][0-9][0-9])",
+			expected: "(^2006-01-02|^2006-01-0[3-9]|^2006-01-[1-9][0-9]|^2006-0[2-9]-[0-9][0-9]|^2006-[1-9][0-9]-[0-9][0-9]|^200[7-9]-[0-9][0-9]-[0-9][0-9]|^20[1-9][0-9]-[0-9][0-9]-[0-9][0-9]|^060102|^06010[3-9]|^0601[1-9][0-9]|^060[2-9][0-9][0-9]|^06[1-9][0-9][0-9][0-9]|^0[7-9][0-9][0-9][0-9][0-9]|^[1-9][0-9][0-9][0-9][0-9][0-9]|^Jan ([ 0][2-9]|[12][0-9]|3[01])|^(Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) )",
 		},
 		{
 			input:    "060102",
 			format:   "060102",
-			expected: "(^2006-01-02|^2006-01-0[3-9]|^2006-01-[1-9][0-9]|^2006-0[2-9]-[0-9][0-9]|^2006-[1-9][0-9]-[0-9][0-9]|^200[7-9]-[0-9][0-9]-[0-9][0-9]|^20[1-9][0-9]-[0-9][0-9]-[0-9][0-9]|^060102|^06010[3-9]|^0601[1-9][0-9]|^060[2-9][0-9][0-9]|^06[1-9][0-9][0-9][0-9]|^0[7-9][0-9][0-9][0-9][0-9]|^[1-9][0-9][0-9][0-9][0-9][0-9])",
+			expected: "(^2006-01-02|^2006-01-0[3-9]|^2006-01-[1-9][0-9]|^2006-0[2-9]-[0-9][0-9]|^2006-[1-9][0-9]-[0-9][0-9]|^200[7-9]-[0-9][0-9]-[0-9][0-9]|^20[1-9][0-9]-[0-9][0-9]-[0-9][0-9]|^060102|^06010[3-9]|^0601[1-9][0-9]|^060[2-9][0-9][0-9]|^06[1-9][0-9][0-9][0-9]|^0[7-9][0-9][0-9][0-9][0-9]|^[1-9][0-9][0-9][0-9][0-9][0-9]|^Jan ([ 0][2-9]|[12][0-9]|3[01])|^(Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) )",
 		},
 		{
 			input:    "2022-01-22",
 			format:   "2006-01-02",
-			expected: "(^2022-01-22|^2022-01-2[3-9]|^2022-01-[3-9][0-9]|^2022-0[2-9]-[0-9][0-9]|^2022-[1-9][0-9]-[0-9][0-9]|^202[3-9]-[0-9][0-9]-[0-9][0-9]|^20[3-9][0-9]-[0-9][0-9]-[0-9][0-9]|^220122|^22012[3-9]|^2201[3-9][0-9]|^220[2-9][0-9][0-9]|^22[1-9][0-9][0-9][0-9]|^2[3-9][0-9][0-9][0-9][0-9]|^[3-9][0-9][0-9][0-9][0-9][0-9])",
+			expected: "(^2022-01-22|^2022-01-2[3-9]|^2022-01-[3-9][0-9]|^2022-0[2-9]-[0-9][0-9]|^2022-[1-9][0-9]-[0-9][0-9]|^202[3-9]-[0-9][0-9]-[0-9][0-9]|^20[3-9][0-9]-[0-9][0-9]-[0-9][0-9]|^220122|^22012[3-9]|^2201[3-9][0-9]|^220[2-9][0-9][0-9]|^22[1-9][0-9][0-9][0-9]|^2[3-9][0-9][0-9][0-9][0-9]|^[3-9][0-9][0-9][0-9][0-9][0-9]|^Jan (2[2-9]|3[01])|^(Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) )",
+		},
+		{
+			input:    "2022-12-31",
+			format:   "2006-01-02",
+			expected: "(^2022-12-31|^2022-12-3[2-9]|^2022-12-[4-9][0-9]|^2022-1[3-9]-[0-9][0-9]|^2022-[2-9][0-9]-[0-9][0-9]|^202[3-9]-[0-9][0-9]-[0-9][0-9]|^20[3-9][0-9]-[0-9][0-9]-[0-9][0-9]|^221231|^22123[2-9]|^2212[4-9][0-9]|^221[3-9][0-9][0-9]|^22[2-9][0-9][0-9][0-9]|^2[3-9][0-9][0-9][0-9][0-9]|^[3-9][0-9][0-9][0-9][0-9][0-9]|^Dec (3[1-1])|^(Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov) )",
+		},
+		{
+			input:    "2022-06-15",
+			format:   "2006-01-02",
+			expected: "(^2022-06-15|^2022-06-1[6-9]|^2022-06-[2-9][0-9]|^2022-0[7-9]-[0-9][0-9]|^2022-[1-9][0-9]-[0-9][0-9]|^202[3-9]-[0-9][0-9]-[0-9][0-9]|^20[3-9][0-9]-[0-9][0-9]-[0-9][0-9]|^220615|^22061[6-9]|^2206[2-9][0-9]|^220[7-9][0-9][0-9]|^22[1-9][0-9][0-9][0-9]|^2[3-9][0-9][0-9][0-9][0-9]|^[3-9][0-9][0-9][0-9][0-9][0-9]|^Jun (1[5-9]|2[0-9]|3[01])|^(Jul|Aug|Sep|Oct|Nov|Dec|Jan|Feb|Mar|Apr|May) )",
 		},
 	}
 
@@ -42,3 +55,193 @@ func TestDateAfter(t *testing.T) {
 		}
 	}
 }
+
+// syslog logs have no year, January logs have to be kept after a since in December
+func TestDateAfterSyslogNewYear(t *testing.T) {
+	since := time.Date(2022, time.December, 20, 0, 0, 0, 0, time.UTC)
+	r := regexp.MustCompile(BetweenDateRegex(&since, false))
+
+	tests := []struct {
+		line     string
+		expected bool
+	}{
+		{line: "Dec 19 23:59:59 host mysqld[123]: WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)", expected: false},
+		{line: "Dec 20 00:00:01 host mysqld[123]: WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)", expected: true},
+		{line: "Jan  2 03:24:26 host mysqld[123]: WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)", expected: true},
+		{line: "Jan 02 03:24:26.654321 host mysqld[123]: WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)", expected: true},
+		{line: "Feb 14 03:24:26 host mysqld[123]: WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)", expected: true},
+	}
+	for _, test := range tests {
+		if r.MatchString(test.line) != test.expected {
+			t.Errorf("expected %v for %s", test.expected, test.line)
+		}
+	}
+}
+
+func TestSearchDateFromLog(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expected       time.Time
+		expectedLayout string
+		expectedOK     bool
+	}{
+		{
+			name:           "mysql",
+			input:          "2023-01-05T03:24:26.123456Z 0 [Note] WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)",
+			expected:       time.Date(2023, time.January, 5, 3, 24, 26, 123456000, time.UTC),
+			expectedLayout: "2006-01-02T15:04:05.000000Z",
+			expectedOK:     true,
+		},
+		{
+			name:           "syslog",
+			input:          "Jan  5 03:24:26 host mysqld[123]: WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)",
+			expected:       time.Date(0, time.January, 5, 3, 24, 26, 0, time.UTC),
+			expectedLayout: "Jan _2 15:04:05",
+			expectedOK:     true,
+		},
+		{
+			name:           "syslog with mysql date in message",
+			input:          "Jan 05 03:24:26 host mysqld[123]: 2023-01-05T03:24:26.123456Z 0 [Note] WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)",
+			expected:       time.Date(2023, time.January, 5, 3, 24, 26, 123456000, time.UTC),
+			expectedLayout: "2006-01-02T15:04:05.000000Z",
+			expectedOK:     true,
+		},
+		{
+			name:           "journald short-precise",
+			input:          "Jan 05 03:24:26.654321 host mysqld[123]: WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)",
+			expected:       time.Date(0, time.January, 5, 3, 24, 26, 654321000, time.UTC),
+			expectedLayout: "Jan _2 15:04:05.000000",
+			expectedOK:     true,
+		},
+		{
+			name:           "journald short-iso",
+			input:          "2023-01-05T03:24:26+0100 host mysqld[123]: WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)",
+			expected:       time.Date(2023, time.January, 5, 2, 24, 26, 0, time.UTC),
+			expectedLayout: "2006-01-02T15:04:05-0700",
+			expectedOK:     true,
+		},
+		{
+			name:       "no date",
+			input:      "View:",
+			expectedOK: false,
+		},
+	}
+
+	for _, test := range tests {
+		d, layout, ok := SearchDateFromLog(test.input)
+		if ok != test.expectedOK || !d.Equal(test.expected) || layout != test.expectedLayout {
+			t.Errorf("testname: %s, expected: %v %s %v, got: %v %s %v", test.name, test.expected, test.expectedLayout, test.expectedOK, d, layout, ok)
+		}
+	}
+}
+
+func TestStripSyslogPrefix(t *testing.T) {
+	tests := []struct {
+		input           string
+		expected        string
+		expectedMessage string
+		expectedSyslog  bool
+	}{
+		{
+			input:           "Jan  5 03:24:26 host mysqld[123]: WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)",
+			expected:        "Jan  5 03:24:26 WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)",
+			expectedMessage: "WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)",
+			expectedSyslog:  true,
+		},
+		{
+			input:           "2023-01-05T03:24:26.123456+0100 host-1.domain mysqld: View:",
+			expected:        "2023-01-05T03:24:26.123456+0100 View:",
+			expectedMessage: "View:",
+			expectedSyslog:  true,
+		},
+		{
+			input:           "Jan 05 03:24:26 host mysqld[123]: 2023-01-05T03:24:26.123456Z 0 [Note] WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)",
+			expected:        "2023-01-05T03:24:26.123456Z 0 [Note] WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)",
+			expectedMessage: "2023-01-05T03:24:26.123456Z 0 [Note] WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)",
+			expectedSyslog:  true,
+		},
+		{
+			input:           "2023-01-05T03:24:26.123456Z 0 [Note] WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)",
+			expected:        "2023-01-05T03:24:26.123456Z 0 [Note] WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)",
+			expectedMessage: "2023-01-05T03:24:26.123456Z 0 [Note] WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 20)",
+		},
+	}
+
+	for _, test := range tests {
+		out := StripSyslogPrefix(test.input)
+		message, syslog := SyslogMessage(test.input)
+		if out != test.expected || message != test.expectedMessage || syslog != test.expectedSyslog {
+			t.Errorf("input: %s, expected: %s, %s %v, got: %s, %s %v", test.input, test.expected, test.expectedMessage, test.expectedSyslog, out, message, syslog)
+		}
+	}
+}
+
+func TestCompleteYear(t *testing.T) {
+	reference := time.Date(2023, time.January, 10, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		input    time.Time
+		expected time.Time
+	}{
+		{
+			input:    time.Date(0, time.January, 5, 3, 24, 26, 0, time.UTC),
+			expected: time.Date(2023, time.January, 5, 3, 24, 26, 0, time.UTC),
+		},
+		{
+			// logs can't be written after the file was modified, it was the previous year
+			input:    time.Date(0, time.December, 30, 3, 24, 26, 0, time.UTC),
+			expected: time.Date(2022, time.December, 30, 3, 24, 26, 0, time.UTC),
+		},
+		{
+			input:    time.Date(2021, time.December, 30, 3, 24, 26, 0, time.UTC),
+			expected: time.Date(2021, time.December, 30, 3, 24, 26, 0, time.UTC),
+		},
+	}
+
+	for _, test := range tests {
+		out := CompleteYear(test.input, reference)
+		if !out.Equal(test.expected) {
+			t.Errorf("input: %v, expected: %v, got: %v", test.input, test.expected, out)
+		}
+	}
+}
+
+func TestDateFromLog(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("no timezone database")
+	}
+	ctx := types.NewLogCtx()
+	ctx.Location = paris
+	ctx.YearReference = time.Date(2023, time.January, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{
+			// "Z" is UTC, whatever the location
+			input:    "2023-01-05T03:24:26.000000Z 0 [Note] WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 12)",
+			expected: time.Date(2023, time.January, 5, 3, 24, 26, 0, time.UTC),
+		},
+		{
+			input:    "2023-01-05T03:24:26.000000-05:00 0 [Note] WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 12)",
+			expected: time.Date(2023, time.January, 5, 8, 24, 26, 0, time.UTC),
+		},
+		{
+			input:    "2023-01-05  03:24:26 0 [Note] WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 12)",
+			expected: time.Date(2023, time.January, 5, 2, 24, 26, 0, time.UTC),
+		},
+		{
+			input:    "Jan  5 03:24:26 db1 mysqld[123]: WSREP: Shifting SYNCED -> DONOR/DESYNCED (TO: 12)",
+			expected: time.Date(2023, time.January, 5, 2, 24, 26, 0, time.UTC),
+		},
+	}
+
+	for _, test := range tests {
+		out := DateFromLog(ctx, test.input)
+		if out == nil || !out.Time.Equal(test.expected) {
+			t.Errorf("input: %s, expected: %v, got: %v", test.input, test.expected, out)
+		}
+	}
+}
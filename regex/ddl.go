@@ -0,0 +1,121 @@
+package regex
+
+import (
+	"regexp"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+func init() {
+	setType(types.DDLRegexType, DDLMap)
+}
+
+// seqnos are -1 until the DDL is replicated
+var regexDDLSeqno = "(?P<" + groupSeqno + ">-?[0-9]+)"
+
+// the connection id is the thread id logged by mysql, right after the date
+var connIDRegex = regexp.MustCompile(" (?P<connid>[0-9]+) \\[(Note|Warning|ERROR|System)\\]")
+
+func connIDFromLog(log string) string {
+	if m := connIDRegex.FindStringSubmatch(log); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// "TO BEGIN: -1, 0 : query" in 5.7, "TO END: 1234: query" in MariaDB 10.4+
+func ddlInternalRegex(prefix string) *regexp.Regexp {
+	return regexp.MustCompile(prefix + " " + regexDDLSeqno + "(, [0-9]+)? ?: (?P<query>.*)")
+}
+
+func ddlBegin(method types.DDLMethod) func(map[string]string, types.LogCtx, string) (types.LogCtx, types.LogDisplayer) {
+	return func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+		ddl := types.NewDDL(method, submatches["query"], connIDFromLog(log), submatches[groupSeqno], DateFromLog(ctx, log))
+		ctx.StartDDL(ddl)
+		return ctx, types.SimpleDisplayer(string(method) + " started: " + ddl.Query)
+	}
+}
+
+// ddlEnd pairs the end with its start, to show how long the cluster or the node was impacted
+func ddlEnd(method types.DDLMethod) func(map[string]string, types.LogCtx, string) (types.LogCtx, types.LogDisplayer) {
+	return func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+		ddl := types.NewDDL(method, submatches["query"], connIDFromLog(log), submatches[groupSeqno], DateFromLog(ctx, log))
+		started, duration, ok := ctx.EndDDL(ddl)
+
+		msg := utils.Paint(utils.BlueText, string(method)) + " " + started.Query + " ("
+		if ok {
+			msg += duration.String() + ", "
+		}
+		msg += utils.Paint(utils.YellowText, started.Impact()) + ")"
+		return ctx, types.SimpleDisplayer(msg)
+	}
+}
+
+var DDLMap = types.RegexMap{
+
+	// only logged with wsrep_debug
+	// 2023-01-01T01:01:01.000000Z 12 [Note] WSREP: TO BEGIN: -1, 0 : ALTER TABLE db.t ADD COLUMN c INT
+	"RegexTOIBegin": &types.LogRegex{
+		Regex:         regexp.MustCompile("TO BEGIN: "),
+		InternalRegex: ddlInternalRegex("TO BEGIN:"),
+		Handler:       ddlBegin(types.TOI),
+		Verbosity:     types.Detailed,
+	},
+
+	// 2023-01-01T01:01:43.000000Z 12 [Note] WSREP: TO END: 1234, 2 : ALTER TABLE db.t ADD COLUMN c INT
+	"RegexTOIEnd": &types.LogRegex{
+		Regex:         regexp.MustCompile("TO END: "),
+		InternalRegex: ddlInternalRegex("TO END:"),
+		Handler:       ddlEnd(types.TOI),
+	},
+
+	// TOI replicated from another node, run by an applier
+	// it is not tracked as ongoing: appliers do not log when they are done, it would never end
+	// 2023-01-01T01:01:01.000000Z 2 [Note] WSREP: Executing Query (ALTER TABLE db.t ADD COLUMN c INT) with write set (-1, 1234)
+	"RegexTOIExecutingQuery": &types.LogRegex{
+		Regex:         regexp.MustCompile("Executing Query \\("),
+		InternalRegex: regexp.MustCompile("Executing Query \\((?P<query>.*)\\) with write set \\((-?[0-9]+, )?" + regexDDLSeqno + "\\)"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			ddl := types.NewDDL(types.TOI, submatches["query"], connIDFromLog(log), submatches[groupSeqno], DateFromLog(ctx, log))
+			return ctx, types.SimpleDisplayer("applying replicated " + string(types.TOI) + "(seqno:" + ddl.Seqno + "): " + ddl.Query)
+		},
+		Verbosity: types.Detailed,
+	},
+
+	// 2023-01-01T01:01:01.000000Z 12 [Note] WSREP: RSU BEGIN: -1, 0 : ALTER TABLE db.t ADD INDEX (c)
+	"RegexRSUBegin": &types.LogRegex{
+		Regex:         regexp.MustCompile("RSU BEGIN: "),
+		InternalRegex: ddlInternalRegex("RSU BEGIN:"),
+		Handler:       ddlBegin(types.RSU),
+		Verbosity:     types.Detailed,
+	},
+
+	// 2023-01-01T01:05:01.000000Z 12 [Note] WSREP: RSU END: 1234, 0 : ALTER TABLE db.t ADD INDEX (c)
+	"RegexRSUEnd": &types.LogRegex{
+		Regex:         regexp.MustCompile("RSU END: "),
+		InternalRegex: ddlInternalRegex("RSU END:"),
+		Handler:       ddlEnd(types.RSU),
+	},
+
+	// 2023-01-01T01:01:01.000000Z 12 [Note] [MY-000000] [WSREP] NBO BEGIN: -1, 0 : ALTER TABLE db.t ADD INDEX (c)
+	"RegexNBOBegin": &types.LogRegex{
+		Regex:         regexp.MustCompile("NBO BEGIN: "),
+		InternalRegex: ddlInternalRegex("NBO BEGIN:"),
+		Handler:       ddlBegin(types.NBO),
+		Verbosity:     types.Detailed,
+	},
+
+	// 2023-01-01T01:05:01.000000Z 12 [Note] [MY-000000] [WSREP] NBO END: 1240, 0 : ALTER TABLE db.t ADD INDEX (c)
+	"RegexNBOEnd": &types.LogRegex{
+		Regex:         regexp.MustCompile("NBO END: "),
+		InternalRegex: ddlInternalRegex("NBO END:"),
+		Handler:       ddlEnd(types.NBO),
+	},
+}
+
+// rsuDesync tells if the node desynced itself because of a RSU schema change
+func rsuDesync(ctx types.LogCtx) bool {
+	_, ok := ctx.OngoingDDL(types.RSU)
+	return ok
+}
@@ -0,0 +1,160 @@
+package regex
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// discoverMarkers are what makes a line worth discovering: errors, warnings, and anything from galera
+var discoverMarkers = []string{"[ERROR]", "[Warning]", "[Galera]", "WSREP:"}
+
+// placeholders replace the variable parts of a line, in this order: the first ones would be broken by the last ones
+// eg: numbers would break every uuids and ips
+var placeholders = []struct {
+	name    string
+	find    *regexp.Regexp
+	pattern string // to match it back in a skeleton regex
+}{
+	{name: "uuid", find: regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`), pattern: `[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`},
+	{name: "ip", find: regexp.MustCompile(`[0-9]{1,3}\.[0-9]{1,3}\.[0-9]{1,3}\.[0-9]{1,3}(:[0-9]{1,5})?`)}, // see placeholderPattern
+	{name: "path", find: regexp.MustCompile(`(^|[ '"(=])/[^ '"(),:]+`), pattern: `/[^ '"(),:]+`},
+	{name: "hash", find: regexp.MustCompile(`\b[0-9a-f]{8}-[0-9a-f]{4}\b|\b[0-9a-f]{8,}\b`), pattern: `[0-9a-f]+(?:-[0-9a-f]{4})?`},
+	{name: "num", find: regexp.MustCompile(`(^|[^a-zA-Z_])[0-9]+(\.[0-9]+)*`), pattern: `[0-9]+(?:\.[0-9]+)*`}, // not in words, eg: md5
+}
+
+var placeholderRegex = regexp.MustCompile(`<(uuid|ip|path|hash|num)>`)
+
+// until the actual message: "0 [Warning] [MY-000000] [Galera] "
+var messagePrefixRegex = regexp.MustCompile(`^(<num> )?(\[[^\]]*\] *)*`)
+
+// IsDiscoverable tells if the line is an error, a warning, or comes from galera
+func IsDiscoverable(line string) bool {
+	for _, marker := range discoverMarkers {
+		if strings.Contains(line, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// LogTemplate normalizes a line, so that the same message logged with different values gives the same template
+// eg: "2023-01-01T01:01:01.000000Z 0 [Warning] WSREP: Failed to connect to 172.17.0.2:4567"
+// gives "<num> [Warning] WSREP: Failed to connect to <ip>"
+func LogTemplate(line string) string {
+	message := strings.TrimSpace(messageWithoutDate(line))
+	for _, placeholder := range placeholders {
+		switch placeholder.name {
+		case "path", "num":
+			// the character before is kept
+			message = placeholder.find.ReplaceAllString(message, "${1}<"+placeholder.name+">")
+		case "hash":
+			// long hexadecimal numbers without letters are left to "num", and words like "deadbeef" are kept
+			message = placeholder.find.ReplaceAllStringFunc(message, func(s string) string {
+				if !strings.ContainsAny(s, "0123456789") || !strings.ContainsAny(s, "abcdef") {
+					return s
+				}
+				return "<hash>"
+			})
+		default:
+			message = placeholder.find.ReplaceAllString(message, "<"+placeholder.name+">")
+		}
+	}
+	return message
+}
+
+func messageWithoutDate(line string) string {
+	line = StripSyslogPrefix(strings.TrimPrefix(line, k8sprefix))
+	for _, layouts := range [][]string{DateLayouts, SyslogDateLayouts} {
+		if _, layout, ok := searchDate(line, layouts); ok {
+			return line[len(layout):]
+		}
+	}
+	return line
+}
+
+// SkeletonRegex gives a regex definition matching every lines of the template, to complete and use with --regex-file
+// Each placeholder is a named group that is displayed
+func SkeletonRegex(key, template string) CustomRegex {
+	var (
+		internal, display strings.Builder
+		longestLiteral    string
+		counts            = map[string]int{}
+	)
+
+	// the display does not need what is before the actual message
+	displayFrom := 0
+	if i := strings.Index(template, "WSREP: "); i >= 0 {
+		displayFrom = i + len("WSREP: ")
+	} else if loc := messagePrefixRegex.FindStringIndex(template); loc != nil {
+		displayFrom = loc[1]
+	}
+
+	literal := func(start, end int) {
+		s := template[start:end]
+		internal.WriteString(regexp.QuoteMeta(s))
+		if len(strings.TrimSpace(s)) > len(longestLiteral) {
+			longestLiteral = strings.TrimSpace(s)
+		}
+		if end <= displayFrom {
+			return
+		}
+		if start < displayFrom {
+			s = template[displayFrom:end]
+		}
+		if strings.Contains(s, "{{") {
+			s = fmt.Sprintf("{{ %q }}", s)
+		}
+		display.WriteString(s)
+	}
+
+	last := 0
+	for _, loc := range placeholderRegex.FindAllStringSubmatchIndex(template, -1) {
+		literal(last, loc[0])
+		last = loc[1]
+
+		name := template[loc[2]:loc[3]]
+		counts[name]++
+		group := name
+		if counts[name] > 1 {
+			group = fmt.Sprintf("%s%d", name, counts[name])
+		}
+		internal.WriteString(placeholderPattern(name, group))
+		if loc[0] >= displayFrom {
+			display.WriteString(displayPlaceholder(name, group))
+		}
+	}
+	literal(last, len(template))
+
+	return CustomRegex{
+		Key:           key,
+		Type:          "events",
+		Regex:         regexp.QuoteMeta(longestLiteral),
+		InternalRegex: internal.String(),
+		Verbosity:     "info", // so that it is displayed by default
+		Display:       strings.TrimSpace(display.String()),
+	}
+}
+
+// only the ip is captured, ports are not useful to identify nodes
+func placeholderPattern(name, group string) string {
+	if name == "ip" {
+		return `(?P<` + group + `>[0-9]{1,3}(?:\.[0-9]{1,3}){3})(?::[0-9]{1,5})?`
+	}
+	for _, placeholder := range placeholders {
+		if placeholder.name == name {
+			return "(?P<" + group + ">" + placeholder.pattern + ")"
+		}
+	}
+	return "(?P<" + group + ">.+)"
+}
+
+func displayPlaceholder(name, group string) string {
+	switch name {
+	case "ip":
+		return "{{ node ." + group + " }}"
+	case "uuid":
+		return "{{ shortUUID ." + group + " }}"
+	}
+	return "{{ ." + group + " }}"
+}
@@ -0,0 +1,76 @@
+package regex
+
+import (
+	"testing"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+func TestLogTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		log      string
+		expected string
+	}{
+		{
+			name:     "ip and numbers",
+			log:      "2023-01-05T03:24:20.100000Z 0 [Warning] [MY-000000] [Galera] Failed to connect to 172.17.0.2:4567, retrying in 3 seconds",
+			expected: "<num> [Warning] [MY-<num>] [Galera] Failed to connect to <ip>, retrying in <num> seconds",
+		},
+		{
+			name:     "syslog prefix is removed",
+			log:      "Jan  5 03:24:21 db2 mysqld[123]: 2023-01-05T03:24:21.100000Z 0 [Warning] [MY-000000] [Galera] Failed to connect to 10.0.0.1:4567, retrying in 3 seconds",
+			expected: "<num> [Warning] [MY-<num>] [Galera] Failed to connect to <ip>, retrying in <num> seconds",
+		},
+		{
+			name:     "uuid and seqno",
+			log:      "2001-01-01T01:01:01.000000Z 0 [Note] WSREP: Found saved state: 8e862473-455e-11e8-a0ca-3fcd8faf3209:-1, safe_to_bootstrap: 0",
+			expected: "<num> [Note] WSREP: Found saved state: <uuid>:-<num>, safe_to_bootstrap: <num>",
+		},
+		{
+			name:     "hashes, numbers in words are kept",
+			log:      "2023-01-05T03:24:31.100000Z 0 [Note] [MY-000000] [Galera] seqno 1234 from a1b2c3d4-8ab7 md5 9f86d081884c7d659a2feaa0c55ad015",
+			expected: "<num> [Note] [MY-<num>] [Galera] seqno <num> from <hash> md5 <hash>",
+		},
+		{
+			name:     "path",
+			log:      "2001-01-01 05:06:12 140000000000 [ERROR] Could not open '/var/lib/mysql/relay-bin.000012'",
+			expected: "<num> [ERROR] Could not open '<path>'",
+		},
+	}
+
+	for _, test := range tests {
+		out := LogTemplate(test.log)
+		if out != test.expected {
+			t.Errorf("testname: %s, expected: %s, got: %s", test.name, test.expected, out)
+		}
+	}
+}
+
+func TestSkeletonRegex(t *testing.T) {
+	utils.SkipColor = true
+	log := "2023-01-05T03:24:20.100000Z 0 [Warning] [MY-000000] [Galera] Failed to connect to 172.17.0.2:4567, retrying in 3 seconds"
+
+	skeleton := SkeletonRegex("RegexTestDiscovered", LogTemplate(log))
+	if skeleton.Display != "Failed to connect to {{ node .ip }}, retrying in {{ .num3 }} seconds" {
+		t.Errorf("unexpected display: %s", skeleton.Display)
+	}
+
+	regex, err := skeleton.toLogRegex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regex.Regex.MatchString(log) {
+		t.Fatalf("regex %s did not match", regex.Regex.String())
+	}
+	ctx := types.NewLogCtx()
+	ctx.IPToNodeName["172.17.0.2"] = "node2"
+	ctx, displayer := regex.Handle(ctx, log)
+	if displayer == nil {
+		t.Fatal("internal regex did not match")
+	}
+	if out := displayer(ctx); out != "Failed to connect to node2, retrying in 3 seconds" {
+		t.Errorf("unexpected display: %s", out)
+	}
+}
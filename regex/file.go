@@ -1,6 +1,9 @@
 package regex
 
-import "regexp"
+import (
+	"regexp"
+	"strings"
+)
 
 var RegexOperatorFileType = regexp.MustCompile(`\"file\":\"/([a-z]+/)+(?P<filetype>[a-z._-]+.log)\"}$`)
 var RegexOperatorShellDebugFileType = regexp.MustCompile(`^\+`)
@@ -31,3 +34,16 @@ func FileType(line string, operator bool) string {
 		return t
 	}
 }
+
+// K8sMessage gives the content of the "log" field of k8s json logs, with its newlines unescaped
+// Records such as "View:" blocks are split across json lines, they can only be matched once unescaped
+func K8sMessage(line string) (string, bool) {
+	if !strings.HasPrefix(line, k8sprefix) {
+		return line, false
+	}
+	msg := strings.TrimPrefix(line, k8sprefix)
+	if i := strings.LastIndex(msg, `","file":"`); i >= 0 {
+		msg = msg[:i]
+	}
+	return strings.TrimSuffix(k8sUnescaper.Replace(msg), "\n"), true
+}
@@ -1,7 +1,11 @@
 package regex
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
 )
 
 func TestFileType(t *testing.T) {
@@ -45,3 +49,36 @@ func TestFileType(t *testing.T) {
 		}
 	}
 }
+
+// operator records are split across json lines, and keep escaped newlines
+func TestK8sMessageView(t *testing.T) {
+	lines := []string{
+		`{"log":"2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] ================================================\nView:\n","file":"/var/lib/mysql/mysqld-error.log"}`,
+		`{"log":"  id: 9f191762-2542-11ee-89be-13bdb1218f0e:9339113\n  status: primary\n  protocol_version: 4\n","file":"/var/lib/mysql/mysqld-error.log"}`,
+		`{"log":"  own_index: 0\n  members(2):\n\t0: 015702fc-32f5-11ed-a4ca-267f97316394, cluster1-0\n","file":"/var/lib/mysql/mysqld-error.log"}`,
+		`{"log":"\t1: 08dd5580-32f7-11ed-a9eb-af5e3d01519e, cluster1-1\n=================================================\n","file":"/var/lib/mysql/mysqld-error.log"}`,
+	}
+	messages := []string{}
+	for _, line := range lines {
+		msg, ok := K8sMessage(line)
+		if !ok {
+			t.Fatalf("not recognized as a k8s log: %s", line)
+		}
+		messages = append(messages, msg)
+	}
+	record := strings.Join(messages, "\n")
+
+	regex := IdentsMap["RegexOwnNameFromView"]
+	if !regex.Regex.MatchString(record) {
+		t.Fatalf("view record not matched: %q", record)
+	}
+	ctx := types.NewLogCtx()
+	ctx.SetState("PRIMARY")
+	ctx, _ = regex.Handle(ctx, record)
+	if ctx.HashToNodeName["015702fc-a4ca"] != "cluster1-0" || ctx.MemberCount != 2 || len(ctx.Views) != 1 {
+		t.Errorf("members not found in the operator view: %v, member count %d, views %v", ctx.HashToNodeName, ctx.MemberCount, ctx.Views)
+	}
+	if !utils.SliceContains(ctx.OwnNames, "cluster1-0") {
+		t.Errorf("expected cluster1-0 to be the local node, got %v", ctx.OwnNames)
+	}
+}
@@ -3,6 +3,7 @@ package regex
 import (
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/ylacancellera/galera-log-explainer/types"
 	"github.com/ylacancellera/galera-log-explainer/utils"
@@ -87,6 +88,54 @@ var IdentsMap = types.RegexMap{
 		Verbosity: types.DebugMySQL,
 	},
 
+	// 2023-05-28T21:18:23.184707-05:00 0 [Note] [MY-000000] [Galera] ================================================
+	// View:
+	//   id: 9f191762-2542-11ee-89be-13bdb1218f0e:9339113
+	//   status: primary
+	//   ...
+	//   own_index: 1
+	//   members(2):
+	//         0: 015702fc-32f5-11ed-a4ca-267f97316394, node1
+	//         1: 08dd5580-32f7-11ed-a9eb-af5e3d01519e, node2
+	// The whole block is needed: own_index and members can only be associated reliably when read together
+	"RegexOwnNameFromView": &types.LogRegex{
+		Regex:         regexp.MustCompile(`View:\n\s+id: `),
+		InternalRegex: regexp.MustCompile(`(?s)own_index: ` + regexIdx + `.*members\(` + regexMembers + `\):`),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+
+			view := parseViewBlock(log)
+			view.Date = DateFromLog(ctx, log)
+			ctx.MyIdx = submatches[groupIdx]
+			membercount, err := strconv.Atoi(submatches[groupMembers])
+			if err == nil {
+				ctx.MemberCount = membercount
+			}
+			ctx.Views = ctx.Views.Add(view)
+
+			names := []string{}
+			for i, member := range view.Members {
+				shorthash := utils.UUIDToShortUUID(member.UUID)
+				nodename := utils.ShortNodeName(member.Name)
+				names = append(names, nodename)
+
+				// nodenames are truncated after 32 characters ...
+				if len(nodename) == 31 {
+					continue
+				}
+				ctx.HashToNodeName[shorthash] = nodename
+				if strconv.Itoa(i) == ctx.MyIdx && (ctx.IsPrimary() || ctx.MemberCount == 1) {
+					ctx.AddOwnHash(shorthash)
+					ctx.AddOwnName(nodename)
+				}
+			}
+
+			return ctx, types.SimpleDisplayer("view members: " + strings.Join(names, ", "))
+		},
+		Verbosity:  types.DebugMySQL,
+		MultiLine:  true,
+		Supersedes: []string{"RegexMemberAssociations", "RegexMemberCount", "RegexOwnIndexFromView"},
+	},
+
 	// My UUID: 6938f4ae-32f4-11ed-be8d-8a0f53f88872
 	"RegexOwnUUID": &types.LogRegex{
 		Regex:         regexp.MustCompile("My UUID"),
@@ -127,6 +176,7 @@ var IdentsMap = types.RegexMap{
 			return ctx, types.SimpleDisplayer("my_idx=" + idx)
 		},
 		Verbosity: types.DebugMySQL,
+		Order:     -1, // before RegexNewComponent
 	},
 
 	/*
@@ -186,6 +236,7 @@ func init_add_regexes() {
 			return IdentsMap["RegexOwnUUIDFromMessageRelay"].Handler(submatches, ctx, log)
 		},
 		Verbosity: types.DebugMySQL,
+		Order:     -1, // before RegexNodeEstablished, which has to know the node own identity
 	}
 
 	IdentsMap["RegexOwnIndexFromView"] = &types.LogRegex{
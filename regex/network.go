@@ -0,0 +1,125 @@
+package regex
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+func init() {
+	setType(types.NetworkRegexType, NetworkMap)
+}
+
+var nodeIPRegexp = regexp.MustCompile(regexNodeIP)
+
+// networkPeer gives the ip of the peer when it is known, its hash otherwise
+func networkPeer(ctx types.LogCtx, hash string) string {
+	if ip, ok := ctx.HashToIP[hash]; ok {
+		return ip
+	}
+	if IsNodeUUID(hash) {
+		hash = utils.UUIDToShortUUID(hash)
+		if ip, ok := ctx.HashToIP[hash]; ok {
+			return ip
+		}
+	}
+	return hash
+}
+
+// networkIssue records the issue for each peer, and displays them
+// misconfigurations will not fix themselves, so they are more visible than transient issues
+func networkIssue(ctx types.LogCtx, log string, peers []string, kind types.NetworkIssueKind, reason string) (types.LogCtx, types.LogDisplayer) {
+	date := DateFromLog(ctx, log)
+	if len(peers) == 0 {
+		ctx.AddNetworkIssue("", kind, reason, date)
+	}
+	for _, peer := range peers {
+		ctx.AddNetworkIssue(peer, kind, reason, date)
+	}
+
+	return ctx, func(ctx types.LogCtx) string {
+		msg := utils.Paint(utils.YellowText, reason)
+		if kind == types.NetworkMisconfiguration {
+			msg = utils.Paint(utils.RedText, "network misconfiguration") + ": " + reason
+		}
+		names := []string{}
+		for _, peer := range peers {
+			names = append(names, types.DisplayPeerSimplestForm(ctx, peer))
+		}
+		if len(names) > 0 {
+			msg += ": " + strings.Join(names, ", ")
+		}
+		return msg
+	}
+}
+
+var NetworkMap = types.RegexMap{
+
+	// 2023-03-31T08:05:57.964535Z 0 [Note] WSREP: handshake failed, my group: 'cluster1', peer group: 'cluster2'
+	"RegexHandshakeWrongGroup": &types.LogRegex{
+		Regex:         regexp.MustCompile("handshake failed, my group"),
+		InternalRegex: regexp.MustCompile("my group: '(?P<mygroup>[^']*)', peer group: '(?P<peergroup>[^']*)'"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			return networkIssue(ctx, log, nil, types.NetworkMisconfiguration, "wrong group, "+submatches["mygroup"]+" here but "+submatches["peergroup"]+" on peer")
+		},
+	},
+
+	// 2023-04-04T22:35:23.487304Z 0 [Warning] [MY-000000] [Galera] Handshake failed: tlsv1 alert decrypt error
+	"RegexHandshakeTLSFailed": &types.LogRegex{
+		Regex:         regexp.MustCompile("Handshake failed: "),
+		InternalRegex: regexp.MustCompile("Handshake failed: (?P<reason>.+)"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			return networkIssue(ctx, log, nil, types.NetworkMisconfiguration, "TLS handshake failed, "+strings.TrimSpace(submatches["reason"]))
+		},
+	},
+
+	// 2023-01-01T01:01:01.000000Z 0 [ERROR] [MY-000000] [Galera] handshake with remote endpoint ssl://172.17.0.3:4567 failed: asio.ssl:336151570: 'sslv3 alert bad certificate' ( 336151570: 'error:14094412:SSL routines:ssl3_read_bytes:sslv3 alert bad certificate')
+	"RegexHandshakeEndpointFailed": &types.LogRegex{
+		Regex:         regexp.MustCompile("handshake with remote endpoint"),
+		InternalRegex: regexp.MustCompile("handshake with remote endpoint " + regexNodeIPMethod + " failed: [^']*'(?P<reason>[^']+)'"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			return networkIssue(ctx, log, []string{submatches[groupNodeIP]}, types.NetworkMisconfiguration, "TLS handshake failed, "+submatches["reason"])
+		},
+	},
+
+	// 2023-01-06T06:59:26.527748Z 0 [Note] WSREP: (9509c194, 'tcp://0.0.0.0:4567') turning message relay requesting on, nonlive peers: tcp://172.17.0.3:4567
+	"RegexMessageRelayOn": &types.LogRegex{
+		Regex:         regexp.MustCompile("turning message relay requesting on"),
+		InternalRegex: regexp.MustCompile("nonlive peers:(?P<peers>.*)"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			peers := nodeIPRegexp.FindAllString(submatches["peers"], -1)
+			return networkIssue(ctx, log, peers, types.NetworkTransient, "unreachable peers, relaying messages")
+		},
+	},
+
+	// 2022-11-29T23:34:51.820009-05:00 0 [Warning] [MY-000000] [Galera] Could not find peer: c0ff4085-5ad7-11ed-8b74-cfeec74147fe
+	"RegexCouldNotFindPeer": &types.LogRegex{
+		Regex:         regexp.MustCompile("Could not find peer:"),
+		InternalRegex: regexp.MustCompile("Could not find peer: " + regexNodeHash),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			return networkIssue(ctx, log, []string{networkPeer(ctx, submatches[groupNodeHash])}, types.NetworkTransient, "could not find peer")
+		},
+		Verbosity: types.Detailed,
+	},
+
+	// 2021-04-22T08:01:05.000581Z 0 [Warning] WSREP: Failed to report last committed 66328091, -110 (Connection timed out)
+	"RegexFailedToReportLastCommitted": &types.LogRegex{
+		Regex:         regexp.MustCompile("Failed to report last committed"),
+		InternalRegex: regexp.MustCompile("Failed to report last committed " + regexSeqno + ", -?[0-9]+ \\((?P<reason>[^)]+)\\)"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			return networkIssue(ctx, log, nil, types.NetworkTransient, "failed to report last committed("+submatches[groupSeqno]+"), "+strings.ToLower(submatches["reason"]))
+		},
+		Verbosity: types.Detailed,
+	},
+
+	// 2023-01-01T01:01:01.000000Z 0 [Warning] WSREP: Failed to send state UUID: -107 (Transport endpoint is not connected)
+	"RegexTransportNotConnected": &types.LogRegex{
+		Regex: regexp.MustCompile("Transport endpoint is not connected"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			return networkIssue(ctx, log, nil, types.NetworkTransient, "transport endpoint is not connected")
+		},
+		Verbosity: types.Detailed,
+	},
+}
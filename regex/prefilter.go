@@ -0,0 +1,141 @@
+package regex
+
+import (
+	"regexp"
+	"regexp/syntax"
+
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+// anchors shorter than this would be found on almost every line, it's cheaper to directly execute the regex
+const minAnchorLength = 3
+
+// Prefilter is the in-process replacement of the grep alternation
+// Literals that must be present for a regex to match ("anchors") are searched all at once,
+// and only the regexes whose anchors were found are executed
+type Prefilter struct {
+	regexes    []*regexp.Regexp
+	automaton  *utils.AhoCorasick
+	owners     []int // anchor index => regex index
+	unanchored []int // regexes we could not get anchors from, they are always executed
+}
+
+func NewPrefilter(regexes []*regexp.Regexp) *Prefilter {
+	p := &Prefilter{regexes: regexes}
+	anchors := []string{}
+
+	for i, re := range regexes {
+		literals := RequiredLiterals(re)
+		if len(literals) == 0 {
+			p.unanchored = append(p.unanchored, i)
+			continue
+		}
+		for _, literal := range literals {
+			anchors = append(anchors, literal)
+			p.owners = append(p.owners, i)
+		}
+	}
+	p.automaton = utils.NewAhoCorasick(anchors)
+	return p
+}
+
+// MatchString reports whether any of the regexes matches the line
+func (p *Prefilter) MatchString(line string) bool {
+	for _, i := range p.unanchored {
+		if p.regexes[i].MatchString(line) {
+			return true
+		}
+	}
+
+	matched := false
+	var tried []int // most lines will not contain any anchor, this avoids allocating for them
+	p.automaton.Search(line, func(anchor int) bool {
+		i := p.owners[anchor]
+		if intSliceContains(tried, i) {
+			return true
+		}
+		tried = append(tried, i)
+		matched = p.regexes[i].MatchString(line)
+		return !matched
+	})
+	return matched
+}
+
+// RequiredLiterals returns literals such that any string matched by the regex
+// contains at least one of them. It returns nothing when no useful literal can be guaranteed
+func RequiredLiterals(re *regexp.Regexp) []string {
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	literals := requiredLiterals(parsed.Simplify())
+	if shortestLiteral(literals) < minAnchorLength {
+		return nil
+	}
+	return literals
+}
+
+func requiredLiterals(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase != 0 {
+			return nil
+		}
+		return []string{string(re.Rune)}
+
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiterals(re.Sub[0])
+
+	case syntax.OpRepeat:
+		if re.Min == 0 {
+			return nil
+		}
+		return requiredLiterals(re.Sub[0])
+
+	// every element is needed, so we can pick the most selective one
+	case syntax.OpConcat:
+		var best []string
+		for _, sub := range re.Sub {
+			literals := requiredLiterals(sub)
+			if shortestLiteral(literals) > shortestLiteral(best) {
+				best = literals
+			}
+		}
+		return best
+
+	// any branch can match, so each of them needs to be guaranteed
+	case syntax.OpAlternate:
+		literals := []string{}
+		for _, sub := range re.Sub {
+			subLiterals := requiredLiterals(sub)
+			if len(subLiterals) == 0 {
+				return nil
+			}
+			literals = append(literals, subLiterals...)
+		}
+		return literals
+	}
+	return nil
+}
+
+func shortestLiteral(literals []string) int {
+	if len(literals) == 0 {
+		return 0
+	}
+	shortest := len(literals[0])
+	for _, literal := range literals[1:] {
+		if len(literal) < shortest {
+			shortest = len(literal)
+		}
+	}
+	return shortest
+}
+
+func intSliceContains(s []int, i int) bool {
+	for _, v := range s {
+		if v == i {
+			return true
+		}
+	}
+	return false
+}
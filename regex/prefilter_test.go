@@ -0,0 +1,80 @@
+package regex
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRequiredLiterals(t *testing.T) {
+	tests := []struct {
+		regex    string
+		expected []string
+	}{
+		{
+			regex:    "Shifting",
+			expected: []string{"Shifting"},
+		},
+		{
+			regex:    "Normal|Received shutdown",
+			expected: []string{"Normal", "Received shutdown"},
+		},
+		{
+			regex:    "requested state transfer.*Selected",
+			expected: []string{"requested state transfer"},
+		},
+		{
+			regex:    ".ode consistency compromi.ed",
+			expected: []string{"ode consistency compromi"},
+		},
+		{
+			regex:    "members.[0-9]+.:",
+			expected: []string{"members"},
+		},
+		{
+			// too short to be useful
+			regex:    "[0-9]: [a-z0-9]+-[a-z0-9]{4}",
+			expected: nil,
+		},
+		{
+			regex:    "(?i)shifting",
+			expected: nil,
+		},
+		{
+			regex:    "(local endpoint for a connection, blacklisting address)|(points to own listening address, blacklisting)",
+			expected: []string{"local endpoint for a connection, blacklisting address", "points to own listening address, blacklisting"},
+		},
+	}
+
+	for _, test := range tests {
+		out := RequiredLiterals(regexp.MustCompile(test.regex))
+		if !cmp.Equal(out, test.expected) {
+			t.Errorf("regex: %s, expected: %q, got: %q", test.regex, test.expected, out)
+		}
+	}
+}
+
+func TestPrefilter(t *testing.T) {
+	p := NewPrefilter([]*regexp.Regexp{
+		regexp.MustCompile("Shifting"),
+		regexp.MustCompile("State transfer to.*complete"),
+		regexp.MustCompile("[0-9]: [a-z0-9]+-[a-z0-9]{4}"),
+	})
+
+	tests := []struct {
+		line     string
+		expected bool
+	}{
+		{line: "2001-01-01T01:01:01.000000Z 0 [Note] WSREP: Shifting OPEN -> PRIMARY (TO: 1)", expected: true},
+		{line: "2001-01-01T01:01:01.000000Z 0 [Note] WSREP: 0.0 (node1): State transfer to 1.0 (node2) complete.", expected: true},
+		{line: "2001-01-01T01:01:01.000000Z 0 [Note] WSREP: 0.0 (node1): State transfer to 1.0 (node2) failed", expected: false},
+		{line: "	0: 015702fc-32f5-11ed-a4ca-267f97316394, node-1", expected: true},
+		{line: "2001-01-01T01:01:01.000000Z 0 [Note] WSREP: nothing to see", expected: false},
+	}
+	for _, test := range tests {
+		if out := p.MatchString(test.line); out != test.expected {
+			t.Errorf("line: %s, expected: %v, got: %v", test.line, test.expected, out)
+		}
+	}
+}
@@ -33,9 +33,10 @@ func SetVerbosity(verbosity types.Verbosity, regexes types.RegexMap) {
 	return
 }
 
+// AllRegexes gives every regexes but the operator ones, in a new map that can be modified
 func AllRegexes() types.RegexMap {
-	IdentsMap.Merge(ViewsMap).Merge(SSTMap).Merge(EventsMap).Merge(StatesMap).Merge(ApplicativeMap)
-	return IdentsMap
+	regexes := types.RegexMap{}
+	return regexes.Merge(IdentsMap).Merge(ViewsMap).Merge(SSTMap).Merge(EventsMap).Merge(StatesMap).Merge(ApplicativeMap).Merge(ResourcesMap).Merge(NetworkMap).Merge(DDLMap)
 }
 
 // general building block wsrep regexes
@@ -52,6 +53,7 @@ var (
 	groupMembers       = "members"
 	groupVersion       = "version"
 	groupErrorMD5      = "errormd5"
+	groupViewID        = "viewid"
 	regexMembers       = "(?P<" + groupMembers + ">[0-9]{1,2})"
 	regexNodeHash      = "(?P<" + groupNodeHash + ">[a-zA-Z0-9-_]+)"
 	regexNodeName      = "(?P<" + groupNodeName + `>[a-zA-Z0-9-_\.]+)`
@@ -64,6 +66,7 @@ var (
 	regexIdx           = "(?P<" + groupIdx + ">-?[0-9]{1,2})"
 	regexVersion       = "(?P<" + groupVersion + ">(5|8|10|11)\\.[0-9]\\.[0-9]{1,2})"
 	regexErrorMD5      = "(?P<" + groupErrorMD5 + ">[a-z0-9]*)"
+	regexViewID        = "(?P<" + groupViewID + ">[a-z0-9-]+:-?[0-9]+)" // eg 9f191762-2542-11ee-89be-13bdb1218f0e:9339113
 )
 
 func IsNodeUUID(s string) bool {
@@ -4,6 +4,7 @@ import (
 	"io/ioutil"
 	"os/exec"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/google/go-cmp/cmp"
@@ -36,6 +37,16 @@ func TestRegexes(t *testing.T) {
 			mapToTest:     EventsMap,
 			key:           "RegexStarting",
 		},
+		{
+			name:          "previous SST phases are dropped",
+			log:           "2001-01-01T01:01:01.000000Z 0 [System] [MY-010116] [Server] /usr/sbin/mysqld (mysqld 8.0.30-22) starting as process 1",
+			inputCtx:      types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhaseMoveBack, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}}},
+			expectedCtx:   types.LogCtx{Version: "8.0.30"},
+			expectedState: "OPEN",
+			expectedOut:   "starting(8.0.30)",
+			mapToTest:     EventsMap,
+			key:           "RegexStarting",
+		},
 		{
 			name:          "8.0.2-22",
 			log:           "2001-01-01T01:01:01.000000Z 0 [System] [MY-010116] [Server] /usr/sbin/mysqld (mysqld 8.0.2-22) starting as process 1",
@@ -277,6 +288,103 @@ func TestRegexes(t *testing.T) {
 			key:         "RegexReversingHistory",
 		},
 
+		{
+			log: "2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] going to give up, state dump for diagnosis:\nevs::proto(evs::proto(8b0c0f77, GATHER, view_id(REG,470a6438,24)), GATHER) {\nknown:\n17a2e064 at tcp://10.0.0.1:4567\n{o=0,s=1,i=0,fs=-1,}\n470a6438 at tcp://10.0.0.2:4567\n{o=1,s=0,i=0,fs=4829091361,jm=\n{v=0,t=4,ut=255,o=1,s=8,sr=-1,as=8,f=4,src=470a6438,nl=(\n\t17a2e064, {o=1,s=1,e=0,ls=-1,vid=view_id(REG,00000000,0),ss=-1,ir=[-1,-1],}\n\t470a6438, {o=1,s=0,e=0,ls=-1,vid=view_id(REG,470a6438,24),ss=8,ir=[9,8],}\n\t8b0c0f77, {o=0,s=0,e=0,ls=-1,vid=view_id(REG,470a6438,24),ss=8,ir=[9,8],}\n)\n},\n}\n8b0c0f77 at \n{o=1,s=0,i=0,fs=-1,}\n }",
+			inputCtx: types.LogCtx{
+				HashToIP:       map[string]string{},
+				HashToNodeName: map[string]string{"17a2e064": "node1"},
+			},
+			expectedCtx: types.LogCtx{
+				HashToIP:       map[string]string{"17a2e064": "10.0.0.1", "470a6438": "10.0.0.2"},
+				HashToNodeName: map[string]string{"17a2e064": "node1"},
+			},
+			expectedOut: "gave up(GATHER): 8b0c0f77 sees node1 as suspected and inactive; 10.0.0.2 sees node1 as suspected, 8b0c0f77 inactive",
+			mapToTest:   ViewsMap,
+			key:         "RegexEVSStateDump",
+		},
+
+		{
+			log: "2001-01-01T01:01:01.000000Z 7 [Note] WSREP: New cluster view: global state: 9f191762-2542-11ee-89be-13bdb1218f0e:9339113, view# 10: Primary, number of nodes: 2, my index: 0, protocol version 3",
+			expectedCtx: types.LogCtx{
+				Views: types.Views{types.View{
+					ID:              "9f191762-2542-11ee-89be-13bdb1218f0e:9339113",
+					Number:          "10",
+					Date:            types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"),
+					Status:          "primary",
+					ProtocolVersion: "3",
+					OwnIndex:        "0",
+					MemberCount:     2,
+				}},
+			},
+			expectedOut: "view#10(primary, n=2)",
+			mapToTest:   ViewsMap,
+			key:         "RegexNewClusterView",
+		},
+
+		{
+			log: "2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] ================================================\nView:\n  id: 9f191762-2542-11ee-89be-13bdb1218f0e:9339113\n  status: primary\n  protocol_version: 4\n  final: no\n  own_index: 1\n  members(2):\n\t0: 015702fc-32f5-11ed-a4ca-267f97316394, node1\n\t1: 08dd5580-32f7-11ed-a9eb-af5e3d01519e, node2\n=================================================",
+			inputCtx: types.LogCtx{
+				OwnHashes:      []string{},
+				OwnNames:       []string{},
+				HashToNodeName: map[string]string{},
+			},
+			inputState:    "PRIMARY",
+			expectedState: "PRIMARY",
+			expectedCtx: types.LogCtx{
+				MyIdx:          "1",
+				MemberCount:    2,
+				OwnHashes:      []string{"08dd5580-a9eb"},
+				OwnNames:       []string{"node2"},
+				HashToNodeName: map[string]string{"015702fc-a4ca": "node1", "08dd5580-a9eb": "node2"},
+				Views: types.Views{types.View{
+					ID:              "9f191762-2542-11ee-89be-13bdb1218f0e:9339113",
+					Date:            types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"),
+					Status:          "primary",
+					ProtocolVersion: "4",
+					OwnIndex:        "1",
+					MemberCount:     2,
+					Members: []types.ViewMember{
+						{UUID: "015702fc-32f5-11ed-a4ca-267f97316394", Name: "node1"},
+						{UUID: "08dd5580-32f7-11ed-a9eb-af5e3d01519e", Name: "node2"},
+					},
+				}},
+			},
+			expectedOut: "view members: node1, node2",
+			mapToTest:   IdentsMap,
+			key:         "RegexOwnNameFromView",
+		},
+		{
+			name: "non-primary view",
+			log:  "2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] ================================================\nView:\n  id: 9f191762-2542-11ee-89be-13bdb1218f0e:9339113\n  status: non-primary\n  protocol_version: 4\n  final: no\n  own_index: 1\n  members(2):\n\t0: 015702fc-32f5-11ed-a4ca-267f97316394, node1\n\t1: 08dd5580-32f7-11ed-a9eb-af5e3d01519e, node2\n=================================================",
+			inputCtx: types.LogCtx{
+				OwnHashes:      []string{},
+				OwnNames:       []string{},
+				HashToNodeName: map[string]string{},
+			},
+			expectedCtx: types.LogCtx{
+				MyIdx:          "1",
+				MemberCount:    2,
+				OwnHashes:      []string{},
+				OwnNames:       []string{},
+				HashToNodeName: map[string]string{"015702fc-a4ca": "node1", "08dd5580-a9eb": "node2"},
+				Views: types.Views{types.View{
+					ID:              "9f191762-2542-11ee-89be-13bdb1218f0e:9339113",
+					Date:            types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"),
+					Status:          "non-primary",
+					ProtocolVersion: "4",
+					OwnIndex:        "1",
+					MemberCount:     2,
+					Members: []types.ViewMember{
+						{UUID: "015702fc-32f5-11ed-a4ca-267f97316394", Name: "node1"},
+						{UUID: "08dd5580-32f7-11ed-a9eb-af5e3d01519e", Name: "node2"},
+					},
+				}},
+			},
+			expectedOut: "view members: node1, node2",
+			mapToTest:   IdentsMap,
+			key:         "RegexOwnNameFromView",
+		},
+
 		{
 			log:         "2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] (90002222-1111, 'ssl://0.0.0.0:4567') Found matching local endpoint for a connection, blacklisting address ssl://127.0.0.1:4567",
 			expectedCtx: types.LogCtx{OwnIPs: []string{"127.0.0.1"}},
@@ -760,6 +868,7 @@ func TestRegexes(t *testing.T) {
 			log:  "2001-01-01T01:01:01.000000Z 0 [Note] WSREP: Member 2.0 (node2) requested state transfer from '*any*'. Selected 0.0 (node1)(SYNCED) as donor.",
 			inputCtx: types.LogCtx{
 				OwnNames: []string{"node2"},
+				SST:      types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhasePrepare, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), End: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}, Failed: true},
 			},
 			expectedCtx: types.LogCtx{
 				OwnNames: []string{"node2"},
@@ -918,7 +1027,43 @@ func TestRegexes(t *testing.T) {
 
 		{
 			log:         "2001-01-01T01:01:01.000000Z 0 [ERROR] [MY-000000] [WSREP] Process completed with error: wsrep_sst_xtrabackup-v2 --role 'donor' --address '172.17.0.2:4444/xtrabackup_sst//1' --socket '/var/lib/mysql/mysql.sock' --datadir '/var/lib/mysql/' --basedir '/usr/' --plugindir '/usr/lib64/mysql/plugin/' --defaults-file '/etc/my.cnf' --defaults-group-suffix '' --mysqld-version '8.0.28-19.1'   '' --gtid '9db0bcdf-b31a-11ed-a398-2a4cfdd82049:1' : 22 (Invalid argument)",
-			expectedOut: "SST error",
+			expectedOut: "SST error, exit code 22 (Invalid argument)",
+			mapToTest:   SSTMap,
+			key:         "RegexSSTError",
+		},
+		{
+			name:        "during streaming",
+			log:         "2001-01-01T01:03:01.000000Z 0 [ERROR] [MY-000000] [WSREP] Process completed with error: wsrep_sst_xtrabackup-v2 --role 'donor' --address '172.17.0.2:4444/xtrabackup_sst//1' --mysqld-version '8.0.28-19.1'   '' --gtid '9db0bcdf-b31a-11ed-a398-2a4cfdd82049:1' : 32 (Broken pipe)",
+			inputCtx:    types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhaseStreaming, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}}},
+			expectedCtx: types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhaseStreaming, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), End: types.NewDate(time.Date(2001, 1, 1, 1, 3, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}, Failed: true}},
+			expectedOut: "SST error, exit code 32 (Broken pipe) during streaming (streaming 2m0s)",
+			mapToTest:   SSTMap,
+			key:         "RegexSSTError",
+		},
+		{
+			name:        "right after the script failed",
+			log:         "2001-01-01T01:03:02.000000Z 0 [ERROR] [MY-000000] [WSREP] Process completed with error: wsrep_sst_xtrabackup-v2 --role 'joiner' --address '172.17.0.3' : 32 (Broken pipe)",
+			inputCtx:    types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhasePrepare, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), End: types.NewDate(time.Date(2001, 1, 1, 1, 3, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}, Failed: true}},
+			expectedCtx: types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhasePrepare, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), End: types.NewDate(time.Date(2001, 1, 1, 1, 3, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}, Failed: true}},
+			expectedOut: "SST error, exit code 32 (Broken pipe) during prepare",
+			mapToTest:   SSTMap,
+			key:         "RegexSSTError",
+		},
+		{
+			name:        "a day after a successful SST",
+			log:         "2001-01-02T01:03:01.000000Z 0 [ERROR] [MY-000000] [WSREP] Process completed with error: wsrep_sst_xtrabackup-v2 --role 'donor' --address '172.17.0.2:4444/xtrabackup_sst//1' : 22 (Invalid argument)",
+			inputCtx:    types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhaseMoveBack, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), End: types.NewDate(time.Date(2001, 1, 1, 1, 3, 0, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}}},
+			expectedCtx: types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhaseMoveBack, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), End: types.NewDate(time.Date(2001, 1, 1, 1, 3, 0, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}}},
+			expectedOut: "SST error, exit code 22 (Invalid argument)",
+			mapToTest:   SSTMap,
+			key:         "RegexSSTError",
+		},
+		{
+			name:        "a day after a failed SST",
+			log:         "2001-01-02T01:03:01.000000Z 0 [ERROR] [MY-000000] [WSREP] Process completed with error: wsrep_sst_xtrabackup-v2 --role 'donor' --address '172.17.0.2:4444/xtrabackup_sst//1' : 22 (Invalid argument)",
+			inputCtx:    types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhasePrepare, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), End: types.NewDate(time.Date(2001, 1, 1, 1, 3, 0, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}, Failed: true}},
+			expectedCtx: types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhasePrepare, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), End: types.NewDate(time.Date(2001, 1, 1, 1, 3, 0, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}, Failed: true}},
+			expectedOut: "SST error, exit code 22 (Invalid argument)",
 			mapToTest:   SSTMap,
 			key:         "RegexSSTError",
 		},
@@ -932,7 +1077,7 @@ func TestRegexes(t *testing.T) {
 
 		{
 			log:           "2001-01-01T01:01:01.000000Z WSREP_SST: [INFO] Proceeding with SST.........",
-			expectedCtx:   types.LogCtx{SST: types.SST{Type: "SST"}},
+			expectedCtx:   types.LogCtx{SST: types.SST{Type: "SST", Phases: []types.SSTPhase{{Name: types.SSTPhaseReceiving, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}}},
 			expectedState: "JOINER",
 			expectedOut:   "receiving SST",
 			mapToTest:     SSTMap,
@@ -941,7 +1086,7 @@ func TestRegexes(t *testing.T) {
 
 		{
 			log:           "2001-01-01T01:01:01.000000Z WSREP_SST: [INFO] Streaming the backup to joiner at 172.17.0.2 4444",
-			expectedCtx:   types.LogCtx{SST: types.SST{ResyncingNode: "172.17.0.2"}},
+			expectedCtx:   types.LogCtx{SST: types.SST{ResyncingNode: "172.17.0.2", Phases: []types.SSTPhase{{Name: types.SSTPhaseStreaming, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}}},
 			expectedState: "DONOR",
 			expectedOut:   "SST to 172.17.0.2",
 			mapToTest:     SSTMap,
@@ -1042,7 +1187,9 @@ func TestRegexes(t *testing.T) {
 		},
 
 		{
-			log:         "2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [WSREP-SST] Preparing the backup at /var/lib/mysql/sst-xb-tmpdir",
+			log:         "2001-01-01T01:03:01.000000Z 0 [Note] [MY-000000] [WSREP-SST] Preparing the backup at /var/lib/mysql/sst-xb-tmpdir",
+			inputCtx:    types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhaseReceiving, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}}},
+			expectedCtx: types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhaseReceiving, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), End: types.NewDate(time.Date(2001, 1, 1, 1, 3, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}, {Name: types.SSTPhasePrepare, Start: types.NewDate(time.Date(2001, 1, 1, 1, 3, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}}},
 			expectedOut: "preparing SST backup",
 			mapToTest:   SSTMap,
 			key:         "RegexPreparingBackup",
@@ -1218,6 +1365,428 @@ func TestRegexes(t *testing.T) {
 			mapToTest:   ApplicativeMap,
 			key:         "RegexInconsistencyRecovery",
 		},
+
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [ERROR] WSREP: Requested size 114209078 for '/var/lib/mysql//galera.cache' exceeds available storage space 1: 28 (No space left on device)",
+			expectedCtx: types.LogCtx{ResourceExhaustion: &types.ResourceExhaustion{Resource: "disk", Date: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}},
+			expectedOut: "no space left to allocate 114209078 bytes for /var/lib/mysql//galera.cache",
+			mapToTest:   ResourcesMap,
+			key:         "RegexGcacheNoSpace",
+		},
+		{
+			log:         "2001-01-01 01:01:01 2101097 [ERROR] mariadbd: Disk full (/tmp/#sql-temptable-.....MAI); waiting for someone to free some space... (errno: 28 \"No space left on device\")",
+			expectedCtx: types.LogCtx{ResourceExhaustion: &types.ResourceExhaustion{Resource: "disk", Date: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02 15:04:05")}},
+			expectedOut: "disk full, waiting for space to write /tmp/#sql-temptable-.....MAI",
+			mapToTest:   ResourcesMap,
+			key:         "RegexDiskFull",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 12 [ERROR] [MY-000000] [Server] Out of memory (Needed 65536 bytes)",
+			expectedCtx: types.LogCtx{ResourceExhaustion: &types.ResourceExhaustion{Resource: "memory", Date: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}},
+			expectedOut: "out of memory",
+			mapToTest:   ResourcesMap,
+			key:         "RegexOutOfMemory",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [ERROR] [MY-000000] [Server] Error in accept: Too many open files",
+			expectedCtx: types.LogCtx{ResourceExhaustion: &types.ResourceExhaustion{Resource: "open files", Date: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}},
+			expectedOut: "too many open files",
+			mapToTest:   ResourcesMap,
+			key:         "RegexTooManyOpenFiles",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [Warning] [MY-010139] [Server] Changed limits: max_open_files: 1024 (requested 5000)",
+			expectedOut: "open files limited to 1024 (requested 5000)",
+			mapToTest:   ResourcesMap,
+			key:         "RegexOpenFilesLimitChanged",
+		},
+		{
+			log:         "2001-01-01 01:01:01 0 [Warning] Could not increase number of max_open_files to more than 1024 (request: 65535)",
+			expectedOut: "open files limited to 1024 (requested 65535)",
+			mapToTest:   ResourcesMap,
+			key:         "RegexOpenFilesLimitNotIncreased",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [Note] WSREP: handshake failed, my group: 'cluster1', peer group: 'cluster2'",
+			expectedCtx: types.LogCtx{NetworkIssues: map[string]types.NetworkIssue{"misconfiguration|": {Kind: types.NetworkMisconfiguration, Reason: "wrong group, cluster1 here but cluster2 on peer", Count: 1, Last: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}},
+			expectedOut: "network misconfiguration: wrong group, cluster1 here but cluster2 on peer",
+			mapToTest:   NetworkMap,
+			key:         "RegexHandshakeWrongGroup",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [Warning] [MY-000000] [Galera] Handshake failed: tlsv1 alert decrypt error",
+			expectedCtx: types.LogCtx{NetworkIssues: map[string]types.NetworkIssue{"misconfiguration|": {Kind: types.NetworkMisconfiguration, Reason: "TLS handshake failed, tlsv1 alert decrypt error", Count: 1, Last: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}},
+			expectedOut: "network misconfiguration: TLS handshake failed, tlsv1 alert decrypt error",
+			mapToTest:   NetworkMap,
+			key:         "RegexHandshakeTLSFailed",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [ERROR] [MY-000000] [Galera] handshake with remote endpoint ssl://172.17.0.3:4567 failed: asio.ssl:336151570: 'sslv3 alert bad certificate' ( 336151570: 'error:14094412:SSL routines:ssl3_read_bytes:sslv3 alert bad certificate')",
+			inputCtx:    types.LogCtx{IPToNodeName: map[string]string{"172.17.0.3": "node3"}},
+			expectedCtx: types.LogCtx{IPToNodeName: map[string]string{"172.17.0.3": "node3"}, NetworkIssues: map[string]types.NetworkIssue{"misconfiguration|172.17.0.3": {Peer: "172.17.0.3", Kind: types.NetworkMisconfiguration, Reason: "TLS handshake failed, sslv3 alert bad certificate", Count: 1, Last: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}},
+			expectedOut: "network misconfiguration: TLS handshake failed, sslv3 alert bad certificate: node3",
+			mapToTest:   NetworkMap,
+			key:         "RegexHandshakeEndpointFailed",
+		},
+		{
+			name:        "known peers",
+			log:         "2001-01-01T01:01:01.000000Z 0 [Note] WSREP: (9509c194, 'tcp://0.0.0.0:4567') turning message relay requesting on, nonlive peers: tcp://172.17.0.3:4567 tcp://172.17.0.4:4567 ",
+			inputCtx:    types.LogCtx{IPToNodeName: map[string]string{"172.17.0.3": "node3"}},
+			expectedCtx: types.LogCtx{IPToNodeName: map[string]string{"172.17.0.3": "node3"}, NetworkIssues: map[string]types.NetworkIssue{"transient|172.17.0.3": {Peer: "172.17.0.3", Kind: types.NetworkTransient, Reason: "unreachable peers, relaying messages", Count: 1, Last: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}, "transient|172.17.0.4": {Peer: "172.17.0.4", Kind: types.NetworkTransient, Reason: "unreachable peers, relaying messages", Count: 1, Last: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}},
+			expectedOut: "unreachable peers, relaying messages: node3, 172.17.0.4",
+			mapToTest:   NetworkMap,
+			key:         "RegexMessageRelayOn",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [Warning] [MY-000000] [Galera] Could not find peer: c0ff4085-5ad7-11ed-8b74-cfeec74147fe",
+			inputCtx:    types.LogCtx{HashToIP: map[string]string{"c0ff4085-8b74": "172.17.0.3"}, IPToNodeName: map[string]string{"172.17.0.3": "node3"}},
+			expectedCtx: types.LogCtx{HashToIP: map[string]string{"c0ff4085-8b74": "172.17.0.3"}, IPToNodeName: map[string]string{"172.17.0.3": "node3"}, NetworkIssues: map[string]types.NetworkIssue{"transient|172.17.0.3": {Peer: "172.17.0.3", Kind: types.NetworkTransient, Reason: "could not find peer", Count: 1, Last: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}},
+			expectedOut: "could not find peer: node3",
+			mapToTest:   NetworkMap,
+			key:         "RegexCouldNotFindPeer",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [Warning] WSREP: Failed to report last committed 66328091, -110 (Connection timed out)",
+			expectedCtx: types.LogCtx{NetworkIssues: map[string]types.NetworkIssue{"transient|": {Kind: types.NetworkTransient, Reason: "failed to report last committed(66328091), connection timed out", Count: 1, Last: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}},
+			expectedOut: "failed to report last committed(66328091), connection timed out",
+			mapToTest:   NetworkMap,
+			key:         "RegexFailedToReportLastCommitted",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [Warning] WSREP: Failed to send state UUID: -107 (Transport endpoint is not connected)",
+			expectedCtx: types.LogCtx{NetworkIssues: map[string]types.NetworkIssue{"transient|": {Kind: types.NetworkTransient, Reason: "transport endpoint is not connected", Count: 1, Last: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}},
+			expectedOut: "transport endpoint is not connected",
+			mapToTest:   NetworkMap,
+			key:         "RegexTransportNotConnected",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] Flow-control interval: [100, 100]",
+			expectedCtx: types.LogCtx{FlowControl: types.FlowControl{Interval: "[100, 100]"}},
+			expectedOut: "flow control interval: [100, 100]",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexFlowControlInterval",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] SENDING FC_STOP (local seqno: 1234, fc_offset: 0, cond: 1)",
+			expectedCtx: types.LogCtx{FlowControl: types.FlowControl{PausedSince: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}},
+			expectedOut: "flow control: asked the cluster to pause writes(seqno:1234)",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexFlowControlStop",
+		},
+		{
+			name:        "already paused",
+			log:         "2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] SENDING FC_STOP (local seqno: 1234, fc_offset: 0, cond: 1)",
+			inputCtx:    types.LogCtx{FlowControl: types.FlowControl{PausedSince: types.NewDate(time.Date(2001, 1, 1, 1, 1, 0, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}},
+			expectedCtx: types.LogCtx{FlowControl: types.FlowControl{PausedSince: types.NewDate(time.Date(2001, 1, 1, 1, 1, 0, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}},
+			expectedOut: "flow control: asked the cluster to pause writes(seqno:1234)",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexFlowControlStop",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] SENDING FC_CONT (local seqno: 1240, fc_offset: 0, cond: 1)",
+			inputCtx:    types.LogCtx{FlowControl: types.FlowControl{PausedSince: types.NewDate(time.Date(2001, 1, 1, 1, 1, 0, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}},
+			expectedCtx: types.LogCtx{FlowControl: types.FlowControl{Pauses: []types.FlowControlPause{{Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 0, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), End: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}}},
+			expectedOut: "flow control: cluster writes were paused by this node for 1s",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexFlowControlCont",
+		},
+		{
+			name:        "pause not found",
+			log:         "2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] SENDING FC_CONT (local seqno: 1240, fc_offset: 0, cond: 1)",
+			expectedOut: "flow control: asked the cluster to resume writes(seqno:1240)",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexFlowControlCont",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [Warning] WSREP: FLOW message from member -12921743687968 in non-primary configuration. Ignored.",
+			expectedCtx: types.LogCtx{FlowControl: types.FlowControl{Ignored: 1}},
+			expectedOut: "flow control from member -12921743687968 ignored, non-primary",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexFlowControlNonPrimary",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [Warning] [MY-000000] [Galera] Action message in non-primary configuration from member 0",
+			expectedOut: "action from member 0 ignored, non-primary",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexActionNonPrimary",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [Galera] trx conflict for key (1,FLAT8)258634b1 d4b5f8f5: source: fb9d6310-ee8b-11ed-8aee-f7542ad73e53 version: 5 local: 1 flags: 1 conn_id: 48 trx_id: 2696 tstamp: 1683653959142522853; state: CERTIFYING:0 seqnos (l: 16, g: 21, s: 19, d: 20) <--X--> source: 0b4e8b3b-ee8b-11ed-a1d2-2f2c1d1c9a7e version: 5 local: 0 flags: 1 conn_id: 12 trx_id: 2690 tstamp: 1683653959142522000; state: COMMITTED:0 seqnos (l: 15, g: 20, s: 18, d: 19)",
+			inputCtx:    types.LogCtx{HashToNodeName: map[string]string{"fb9d6310-8aee": "node1", "0b4e8b3b-a1d2": "node2"}},
+			expectedCtx: types.LogCtx{HashToNodeName: map[string]string{"fb9d6310-8aee": "node1", "0b4e8b3b-a1d2": "node2"}, CertificationConflicts: types.CertificationConflicts{{Kind: types.CertificationFailure, Date: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), ConnID: "48", TrxID: "2696", Seqno: "21", Source: "fb9d6310-ee8b-11ed-8aee-f7542ad73e53", WinnerSource: "0b4e8b3b-ee8b-11ed-a1d2-2f2c1d1c9a7e"}}},
+			expectedOut: "certification failed(seqno:21): trx from node1 lost against node2",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexCertificationFailure",
+		},
+		{
+			log:         "2001-01-01 01:01:01 35 [Note] WSREP: cluster conflict due to high priority abort for threads:",
+			expectedCtx: types.LogCtx{CertificationConflicts: types.CertificationConflicts{{Kind: types.BFAbort, Date: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02 15:04:05")}}},
+			expectedOut: "local trx brute force aborted by a replicated one",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexBFAbort",
+		},
+		{
+			log:         "2001-01-01 01:01:01 35 [Note] WSREP: MDL BF-BF conflict",
+			expectedCtx: types.LogCtx{CertificationConflicts: types.CertificationConflicts{{Kind: types.MDLBFBFConflict, Date: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02 15:04:05")}}},
+			expectedOut: "MDL BF-BF conflict between replicated trx",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexMDLBFBFConflict",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 51 [Warning] [MY-000000] [Galera] failed to replay trx: source: fb9d6310-ee8b-11ed-8aee-f7542ad73e53 version: 5 local: 1 flags: 1 conn_id: 48 trx_id: 2696 tstamp: 1683653959142522853; state: EXECUTING:0->REPLICATING:782->CERTIFYING:3509->APPLYING:3748->COMMITTING:1343->COMMITTED:-1",
+			expectedCtx: types.LogCtx{CertificationConflicts: types.CertificationConflicts{{Kind: types.ReplayFailure, Date: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), ConnID: "48", TrxID: "2696", Source: "fb9d6310-ee8b-11ed-8aee-f7542ad73e53"}}},
+			expectedOut: "failed to replay trx(conn_id:48, trx_id:2696)",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexReplayFailed",
+		},
+		{
+			name:        "already counted by failed to replay",
+			log:         "2001-01-01T01:01:01.000000Z 51 [Warning] [MY-000000] [Galera] Invalid state in replay for trx source: fb9d6310-ee8b-11ed-8aee-f7542ad73e53 version: 5 local: 1 flags: 1 conn_id: 48 trx_id: 2696 tstamp: 1683653959142522853; state: EXECUTING:0->REPLICATING:782->CERTIFYING:3509->APPLYING:3748->COMMITTING:1343->COMMITTED:-1 (FATAL)",
+			inputCtx:    types.LogCtx{CertificationConflicts: types.CertificationConflicts{{Kind: types.ReplayFailure, Date: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), ConnID: "48", TrxID: "2696", Source: "fb9d6310-ee8b-11ed-8aee-f7542ad73e53"}}},
+			expectedCtx: types.LogCtx{CertificationConflicts: types.CertificationConflicts{{Kind: types.ReplayFailure, Date: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), ConnID: "48", TrxID: "2696", Source: "fb9d6310-ee8b-11ed-8aee-f7542ad73e53"}}},
+			expectedOut: "invalid state in replay(conn_id:48, trx_id:2696)",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexReplayInvalidState",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 10 [ERROR] Slave SQL: Could not execute Write_rows event on table test.t1; Can't find record in 't1', Error_code: 1032; handler error HA_ERR_KEY_NOT_FOUND; the event's master log FIRST, end_log_pos 164, Error_code: 1032",
+			inputCtx:    types.LogCtx{OwnNames: []string{"node1"}},
+			expectedCtx: types.LogCtx{OwnNames: []string{"node1"}, Conflicts: types.Conflicts{&types.Conflict{InitiatedBy: []string{"node1"}, Table: "test.t1", VotePerNode: map[string]types.ConflictVote{"node1": {Error: "Can't find record in 't1'", ErrorCode: "1032"}}}}},
+			expectedOut: "could not apply Write_rows on test.t1: error 1032(HA_ERR_KEY_NOT_FOUND)",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexApplierError",
+		},
+		{
+			name:        "8.0",
+			log:         "2001-01-01T01:01:01.000000Z 10 [ERROR] [MY-010584] [Repl] Slave SQL: Could not execute Update_rows event on table test.t1; Can't find record in 't1', Error_code: 1032; handler error HA_ERR_KEY_NOT_FOUND; the event's master log FIRST, end_log_pos 164, Error_code: MY-001032",
+			inputCtx:    types.LogCtx{OwnNames: []string{"node1"}},
+			expectedCtx: types.LogCtx{OwnNames: []string{"node1"}, Conflicts: types.Conflicts{&types.Conflict{InitiatedBy: []string{"node1"}, Table: "test.t1", VotePerNode: map[string]types.ConflictVote{"node1": {Error: "Can't find record in 't1'", ErrorCode: "1032"}}}}},
+			expectedOut: "could not apply Update_rows on test.t1: error 1032(HA_ERR_KEY_NOT_FOUND)",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexApplierError",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 10 [ERROR] WSREP: Failed to apply trx: source: 8bd1a1a4-ee8b-11ed-8aee-f7542ad73e53 version: 3 local: 0 state: APPLYING flags: 1 conn_id: 3 trx_id: 1234 seqnos (l: 5, g: 1234, s: 1233, d: 1233, ts: 12345)",
+			inputCtx:    types.LogCtx{OwnNames: []string{"node1"}, Conflicts: types.Conflicts{&types.Conflict{InitiatedBy: []string{"node1"}, Table: "test.t1", VotePerNode: map[string]types.ConflictVote{"node1": {ErrorCode: "1032"}}}}},
+			expectedCtx: types.LogCtx{OwnNames: []string{"node1"}, Conflicts: types.Conflicts{&types.Conflict{Seqno: "1234", InitiatedBy: []string{"node1"}, Table: "test.t1", VotePerNode: map[string]types.ConflictVote{"node1": {ErrorCode: "1032"}}}}},
+			expectedOut: "failed to apply trx(seqno:1234) on test.t1",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexFailedToApplyTrx",
+		},
+		{
+			name:        "no applier error found",
+			log:         "2001-01-01T01:01:01.000000Z 10 [ERROR] WSREP: Failed to apply trx 1234 4 times",
+			inputCtx:    types.LogCtx{OwnNames: []string{"node1"}},
+			expectedCtx: types.LogCtx{OwnNames: []string{"node1"}, Conflicts: types.Conflicts{&types.Conflict{Seqno: "1234", InitiatedBy: []string{"node1"}, VotePerNode: map[string]types.ConflictVote{"node1": {}}}}},
+			expectedOut: "failed to apply trx(seqno:1234)",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexFailedToApplyTrx",
+		},
+		{
+			name:                 "seqno already known",
+			log:                  "2001-01-01T01:01:01.000000Z 10 [ERROR] WSREP: Failed to apply trx 1234 4 times",
+			inputCtx:             types.LogCtx{OwnNames: []string{"node1"}, Conflicts: types.Conflicts{&types.Conflict{Seqno: "1234", InitiatedBy: []string{"node1"}, VotePerNode: map[string]types.ConflictVote{"node1": {}}}}},
+			expectedCtx:          types.LogCtx{OwnNames: []string{"node1"}, Conflicts: types.Conflicts{&types.Conflict{Seqno: "1234", InitiatedBy: []string{"node1"}, VotePerNode: map[string]types.ConflictVote{"node1": {}}}}},
+			displayerExpectedNil: true,
+			mapToTest:            ApplicativeMap,
+			key:                  "RegexFailedToApplyTrx",
+		},
+
+		{
+			log:         "2001-01-01T01:01:01.000000Z 12 [Note] WSREP: TO BEGIN: -1, 0 : ALTER TABLE db.t   ADD COLUMN c INT",
+			expectedCtx: types.LogCtx{OngoingDDLs: map[string]types.DDL{"TOI 12": {Method: types.TOI, Query: "ALTER TABLE db.t ADD COLUMN c INT", ConnID: "12", Seqno: "-1", Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}},
+			expectedOut: "TOI started: ALTER TABLE db.t ADD COLUMN c INT",
+			mapToTest:   DDLMap,
+			key:         "RegexTOIBegin",
+		},
+		{
+			log:         "2001-01-01T01:01:43.000000Z 12 [Note] WSREP: TO END: 1234, 2 : ALTER TABLE db.t ADD COLUMN c INT",
+			inputCtx:    types.LogCtx{OngoingDDLs: map[string]types.DDL{"TOI 12": {Method: types.TOI, Query: "ALTER TABLE db.t ADD COLUMN c INT", ConnID: "12", Seqno: "-1", Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}},
+			expectedCtx: types.LogCtx{OngoingDDLs: map[string]types.DDL{}},
+			expectedOut: "TOI ALTER TABLE db.t ADD COLUMN c INT (42s, blocked cluster)",
+			mapToTest:   DDLMap,
+			key:         "RegexTOIEnd",
+		},
+		{
+			name:        "mariadb, no start",
+			log:         "2001-01-01 01:01:43 12 [Note] WSREP: TO END: 1234: ALTER TABLE db.t ADD COLUMN c INT",
+			expectedOut: "TOI ALTER TABLE db.t ADD COLUMN c INT (blocked cluster)",
+			mapToTest:   DDLMap,
+			key:         "RegexTOIEnd",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 2 [Note] WSREP: Executing Query (ALTER TABLE db.t ADD COLUMN c INT) with write set (-1, 1234)",
+			expectedCtx: types.LogCtx{},
+			expectedOut: "applying replicated TOI(seqno:1234): ALTER TABLE db.t ADD COLUMN c INT",
+			mapToTest:   DDLMap,
+			key:         "RegexTOIExecutingQuery",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 12 [Note] WSREP: RSU BEGIN: -1, 0 : ALTER TABLE db.t ADD INDEX (c)",
+			expectedCtx: types.LogCtx{OngoingDDLs: map[string]types.DDL{"RSU 12": {Method: types.RSU, Query: "ALTER TABLE db.t ADD INDEX (c)", ConnID: "12", Seqno: "-1", Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}},
+			expectedOut: "RSU started: ALTER TABLE db.t ADD INDEX (c)",
+			mapToTest:   DDLMap,
+			key:         "RegexRSUBegin",
+		},
+		{
+			log:         "2001-01-01T01:01:43.000000Z 12 [Note] WSREP: RSU END: 1234, 0 : ALTER TABLE db.t ADD INDEX (c)",
+			inputCtx:    types.LogCtx{OngoingDDLs: map[string]types.DDL{"RSU 12": {Method: types.RSU, Query: "ALTER TABLE db.t ADD INDEX (c)", ConnID: "12", Seqno: "-1", Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}},
+			expectedCtx: types.LogCtx{OngoingDDLs: map[string]types.DDL{}},
+			expectedOut: "RSU ALTER TABLE db.t ADD INDEX (c) (42s, node desynced)",
+			mapToTest:   DDLMap,
+			key:         "RegexRSUEnd",
+		},
+		{
+			name:        "during RSU",
+			log:         "2001-01-01T01:01:01.000000Z 12 [Note] WSREP: Member 0.0 (node) desyncs itself from group",
+			inputCtx:    types.LogCtx{OwnNames: []string{"node"}, OngoingDDLs: map[string]types.DDL{"RSU 12": {Method: types.RSU, Query: "ALTER TABLE db.t ADD INDEX (c)", ConnID: "12"}}},
+			expectedCtx: types.LogCtx{OwnNames: []string{"node"}, Desynced: true, OngoingDDLs: map[string]types.DDL{"RSU 12": {Method: types.RSU, Query: "ALTER TABLE db.t ADD INDEX (c)", ConnID: "12"}}},
+			expectedOut: "desyncs itself from group for RSU",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexDesync",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 12 [Note] [MY-000000] [WSREP] NBO BEGIN: -1, 0 : ALTER TABLE db.t ADD INDEX (c)",
+			expectedCtx: types.LogCtx{OngoingDDLs: map[string]types.DDL{"NBO 12": {Method: types.NBO, Query: "ALTER TABLE db.t ADD INDEX (c)", ConnID: "12", Seqno: "-1", Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}},
+			expectedOut: "NBO started: ALTER TABLE db.t ADD INDEX (c)",
+			mapToTest:   DDLMap,
+			key:         "RegexNBOBegin",
+		},
+		{
+			log:         "2001-01-01T01:01:43.000000Z 12 [Note] [MY-000000] [WSREP] NBO END: 1240, 0 : ALTER TABLE db.t ADD INDEX (c)",
+			inputCtx:    types.LogCtx{OngoingDDLs: map[string]types.DDL{"NBO 12": {Method: types.NBO, Query: "ALTER TABLE db.t ADD INDEX (c)", ConnID: "12", Seqno: "-1", Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}},
+			expectedCtx: types.LogCtx{OngoingDDLs: map[string]types.DDL{}},
+			expectedOut: "NBO ALTER TABLE db.t ADD INDEX (c) (42s, non-blocking)",
+			mapToTest:   DDLMap,
+			key:         "RegexNBOEnd",
+		},
+
+		{
+			log:         "2001-01-01T01:01:01.000000Z 48 [ERROR] WSREP: transaction size limit (2147483647) exceeded: 2147483648",
+			expectedCtx: types.LogCtx{LargeTransactions: types.LargeTransactions{Counts: map[types.LargeTransactionKind]int{types.WriteSetTooLarge: 1}, Largest: 2147483648, Last: &types.LargeTransaction{Kind: types.WriteSetTooLarge, Date: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), ConnID: "48", Size: 2147483648}}},
+			expectedOut: "write set too large: 2.0GiB (limit 2.0GiB), conn_id:48",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexTrxSizeLimitExceeded",
+		},
+		{
+			name:        "without size",
+			log:         "2001-01-01T01:01:01.000000Z 48 [ERROR] WSREP: transaction size exceeds wsrep_max_ws_size",
+			inputCtx:    types.LogCtx{LargeTransactions: types.LargeTransactions{Counts: map[types.LargeTransactionKind]int{types.WriteSetTooLarge: 1}, Largest: 2147483648}},
+			expectedCtx: types.LogCtx{LargeTransactions: types.LargeTransactions{Counts: map[types.LargeTransactionKind]int{types.WriteSetTooLarge: 2}, Largest: 2147483648, Last: &types.LargeTransaction{Kind: types.WriteSetTooLarge, Date: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), ConnID: "48"}}},
+			expectedOut: "write set too large, conn_id:48",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexTrxSizeLimitExceeded",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 48 [Warning] [MY-000000] [Galera] Maximum writeset size exceeded by 1: 2147483649",
+			expectedCtx: types.LogCtx{LargeTransactions: types.LargeTransactions{Counts: map[types.LargeTransactionKind]int{types.WriteSetTooLarge: 1}, Largest: 2147483649, Last: &types.LargeTransaction{Kind: types.WriteSetTooLarge, Date: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), ConnID: "48", Size: 2147483649}}},
+			expectedOut: "write set too large: 2.0GiB, conn_id:48",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexWriteSetSizeExceeded",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 48 [Warning] [MY-000000] [Galera] Writeset size 1610612736 exceeds the limit",
+			expectedCtx: types.LogCtx{LargeTransactions: types.LargeTransactions{Counts: map[types.LargeTransactionKind]int{types.WriteSetTooLarge: 1}, Largest: 1610612736, Last: &types.LargeTransaction{Kind: types.WriteSetTooLarge, Date: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), ConnID: "48", Size: 1610612736}}},
+			expectedOut: "write set too large: 1.5GiB, conn_id:48",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexWriteSetSizeExceeded",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 14 [ERROR] [MY-000000] [WSREP] Failed to apply SR fragment for trx_id: 2696, seqno: 1234",
+			expectedCtx: types.LogCtx{LargeTransactions: types.LargeTransactions{Counts: map[types.LargeTransactionKind]int{types.SRFragmentApplyFailure: 1}, Last: &types.LargeTransaction{Kind: types.SRFragmentApplyFailure, Date: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), ConnID: "14", TrxID: "2696"}}},
+			expectedOut: "failed to apply streaming replication fragment(trx_id:2696)",
+			mapToTest:   ApplicativeMap,
+			key:         "RegexSRFragmentApplyFailed",
+		},
+
+		{
+			log:         "2001-01-01  1:01:01 0 [Note] WSREP: WSREP_SST: [INFO] Flushing tables for SST... (20010101 01:01:01.000)",
+			expectedCtx: types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhaseBackup, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02 15:04:05")}}}},
+			expectedOut: "flushing tables for SST",
+			mapToTest:   SSTMap,
+			key:         "RegexSSTFlushingTables",
+		},
+		{
+			log:         "2001-01-01  1:01:02 0 [Note] WSREP: WSREP_SST: [INFO] Tables flushed. (20010101 01:01:02.000)",
+			inputCtx:    types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhaseBackup, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02 15:04:05")}}}},
+			expectedCtx: types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhaseBackup, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02 15:04:05"), End: types.NewDate(time.Date(2001, 1, 1, 1, 1, 2, 0, time.UTC), "2006-01-02 15:04:05")}, {Name: types.SSTPhaseStreaming, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 2, 0, time.UTC), "2006-01-02 15:04:05")}}}},
+			expectedOut: "tables flushed, streaming SST",
+			mapToTest:   SSTMap,
+			key:         "RegexSSTTablesFlushed",
+		},
+		{
+			name:        "same phase",
+			log:         "2001-01-01T01:03:01.000000Z 0 [Note] [MY-000000] [WSREP-SST] ............Waiting for SST streaming to complete!",
+			inputCtx:    types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhaseReceiving, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}}},
+			expectedCtx: types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhaseReceiving, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}}},
+			expectedOut: "waiting for SST streaming",
+			mapToTest:   SSTMap,
+			key:         "RegexSSTWaitingStreaming",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [WSREP-SST] Waiting for donor keyring file",
+			expectedCtx: types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhaseKeyring, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}}},
+			expectedOut: "SST keyring transfer",
+			mapToTest:   SSTMap,
+			key:         "RegexSSTKeyring",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [WSREP-SST] Moving the backup to /var/lib/mysql/",
+			expectedCtx: types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhaseMoveBack, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}}},
+			expectedOut: "moving SST backup to datadir",
+			mapToTest:   SSTMap,
+			key:         "RegexSSTMovingBackup",
+		},
+		{
+			log:         "2001-01-01T01:03:01.000000Z 0 [Note] [MY-000000] [WSREP-SST] Total time on joiner: 120 seconds",
+			inputCtx:    types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhaseReceiving, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 0, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), End: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}, {Name: types.SSTPhasePrepare, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}}},
+			expectedCtx: types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhaseReceiving, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 0, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), End: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}, {Name: types.SSTPhasePrepare, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), End: types.NewDate(time.Date(2001, 1, 1, 1, 3, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}}},
+			expectedOut: "SST script done in 120s on joiner (receiving 1s, prepare 2m0s)",
+			mapToTest:   SSTMap,
+			key:         "RegexSSTTotalTime",
+		},
+		{
+			log:         "2001-01-01T01:03:01.000000Z 0 [ERROR] [MY-000000] [WSREP-SST] xtrabackup finished with error: 1.  Check /var/lib/mysql//innobackup.backup.log",
+			inputCtx:    types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhasePrepare, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}}},
+			expectedCtx: types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhasePrepare, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), End: types.NewDate(time.Date(2001, 1, 1, 1, 3, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}, Failed: true}},
+			expectedOut: "xtrabackup failed, exit code 1, see innobackup.backup.log during prepare (prepare 2m0s)",
+			mapToTest:   SSTMap,
+			key:         "RegexSSTBackupFinishedWithError",
+		},
+		{
+			name:        "mariabackup, no phase",
+			log:         "2001-01-01  1:01:01 0 [ERROR] WSREP: WSREP_SST: [ERROR] mariabackup finished with error: 1.  Check /var/lib/mysql//mariabackup.backup.log (20010101 01:01:01.000)",
+			expectedOut: "mariabackup failed, exit code 1, see mariabackup.backup.log",
+			mapToTest:   SSTMap,
+			key:         "RegexSSTBackupFinishedWithError",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [WSREP-SST] Cleanup after exit with status:32",
+			expectedOut: "SST script exited with code 32",
+			mapToTest:   SSTMap,
+			key:         "RegexSSTCleanupExitStatus",
+		},
+		{
+			name:                 "success",
+			log:                  "2001-01-01T01:03:01.000000Z 0 [Note] [MY-000000] [WSREP-SST] Cleanup after exit with status:0",
+			inputCtx:             types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhaseMoveBack, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}}},
+			expectedCtx:          types.LogCtx{SST: types.SST{Phases: []types.SSTPhase{{Name: types.SSTPhaseMoveBack, Start: types.NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z"), End: types.NewDate(time.Date(2001, 1, 1, 1, 3, 1, 0, time.UTC), "2006-01-02T15:04:05.000000Z")}}}},
+			displayerExpectedNil: true,
+			mapToTest:            SSTMap,
+			key:                  "RegexSSTCleanupExitStatus",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [ERROR] [MY-011825] [Xtrabackup] failed to execute query 'LOCK INSTANCE FOR BACKUP' : 1227 (42000) Access denied",
+			expectedOut: "xtrabackup error: failed to execute query 'LOCK INSTANCE FOR BACKUP' : 1227 (42000) Access denied",
+			mapToTest:   SSTMap,
+			key:         "RegexXtrabackupError",
+		},
+		{
+			log:         "2001-01-01T01:01:01.000000Z 0 [Note] [MY-011825] [Xtrabackup] completed OK!",
+			expectedOut: "xtrabackup completed OK",
+			mapToTest:   SSTMap,
+			key:         "RegexXtrabackupCompletedOK",
+		},
 	}
 
 	for _, test := range tests {
@@ -1259,7 +1828,21 @@ func TestRegexes(t *testing.T) {
 func testRegexFromMap(t *testing.T, log string, regex *types.LogRegex) error {
 	m := types.RegexMap{"test": regex}
 
-	return testActualGrepOnLog(t, log, m.Compile()[0])
+	// grep can't be used as a reference for records, it only works line by line
+	if regex.MultiLine {
+		if !NewPrefilter(m.Regexes()).MatchString(log) {
+			return errors.New("native engine did not match the record")
+		}
+		return nil
+	}
+
+	// the native engine is expected to give the very same results as grep
+	nativeMatched := NewPrefilter(m.Regexes()).MatchString(log)
+	err := testActualGrepOnLog(t, log, m.Compile()[0])
+	if nativeMatched != (err == nil) {
+		return errors.Errorf("native engine and grep disagree, native matched: %v, grep error: %v", nativeMatched, err)
+	}
+	return err
 }
 
 func testActualGrepOnLog(t *testing.T, log, regex string) error {
@@ -0,0 +1,105 @@
+package regex
+
+import (
+	"regexp"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+func init() {
+	setType(types.ResourcesRegexType, ResourcesMap)
+}
+
+// resourceExhausted marks the node as starved, until it restarts
+func resourceExhausted(ctx types.LogCtx, log, resource string) types.LogCtx {
+	ctx.ResourceExhaustion = &types.ResourceExhaustion{Resource: resource, Date: DateFromLog(ctx, log)}
+	return ctx
+}
+
+var ResourcesMap = types.RegexMap{
+
+	// 2023-06-07T02:50:17.288285-06:00 0 [ERROR] WSREP: Requested size 114209078 for '/var/lib/mysql//galera.cache' exceeds available storage space 1: 28 (No space left on device)
+	"RegexGcacheNoSpace": &types.LogRegex{
+		Regex:         regexp.MustCompile("exceeds available storage space"),
+		InternalRegex: regexp.MustCompile("Requested size (?P<size>[0-9]+) for '(?P<path>[^']+)'"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			ctx = resourceExhausted(ctx, log, "disk")
+			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "no space left") + " to allocate " + submatches["size"] + " bytes for " + submatches["path"])
+		},
+		// RegexNoSpaceLeft also matches, but knows less
+		Order:    -1,
+		Terminal: true,
+	},
+
+	// 2023-01-01 11:33:15 2101097 [ERROR] mariadbd: Disk full (/tmp/#sql-temptable-.....MAI); waiting for someone to free some space... (errno: 28 "No space left on device")
+	"RegexDiskFull": &types.LogRegex{
+		Regex:         regexp.MustCompile("Disk full"),
+		InternalRegex: regexp.MustCompile("Disk full \\((?P<path>[^)]*)\\)"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			ctx = resourceExhausted(ctx, log, "disk")
+			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "disk full") + ", waiting for space to write " + submatches["path"])
+		},
+		Order:    -1,
+		Terminal: true,
+	},
+
+	"RegexNoSpaceLeft": &types.LogRegex{
+		Regex: regexp.MustCompile("No space left on device"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			ctx = resourceExhausted(ctx, log, "disk")
+			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "no space left on device"))
+		},
+	},
+
+	// 2023-01-01T01:01:01.000000Z 12 [ERROR] [MY-000000] [Server] Out of memory (Needed 65536 bytes)
+	"RegexOutOfMemory": &types.LogRegex{
+		Regex: regexp.MustCompile("Out of memory"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			ctx = resourceExhausted(ctx, log, "memory")
+			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "out of memory"))
+		},
+	},
+
+	// 2023-01-01 01:01:01 0 [ERROR] Error in accept: Too many open files
+	"RegexTooManyOpenFiles": &types.LogRegex{
+		Regex: regexp.MustCompile("Too many open files"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			ctx = resourceExhausted(ctx, log, "open files")
+			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "too many open files"))
+		},
+	},
+
+	// the limits are not exhausted yet, but it usually explains why they will be
+	// 2023-01-01T01:01:01.000000Z 0 [Warning] [MY-010139] [Server] Changed limits: max_open_files: 1024 (requested 5000)
+	"RegexOpenFilesLimitChanged": &types.LogRegex{
+		Regex:         regexp.MustCompile("Changed limits: max_open_files"),
+		InternalRegex: regexp.MustCompile("max_open_files: (?P<limit>[0-9]+) \\(requested (?P<requested>[0-9]+)\\)"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			return ctx, types.SimpleDisplayer(utils.Paint(utils.YellowText, "open files limited") + " to " + submatches["limit"] + " (requested " + submatches["requested"] + ")")
+		},
+		Verbosity: types.Detailed,
+	},
+
+	// 2023-01-01 01:01:01 0 [Warning] Could not increase number of max_open_files to more than 1024 (request: 65535)
+	"RegexOpenFilesLimitNotIncreased": &types.LogRegex{
+		Regex:         regexp.MustCompile("Could not increase number of max_open_files"),
+		InternalRegex: regexp.MustCompile("max_open_files to more than (?P<limit>[0-9]+) \\(request: (?P<requested>[0-9]+)\\)"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			return ctx, types.SimpleDisplayer(utils.Paint(utils.YellowText, "open files limited") + " to " + submatches["limit"] + " (requested " + submatches["requested"] + ")")
+		},
+		Verbosity: types.Detailed,
+	},
+}
+
+// resourceConsequences are failures often caused by a node running out of resources
+var resourceConsequences = []string{
+	"RegexAborting", "RegexAssertionFailure", "RegexGotSignal6", "RegexGotSignal11",
+	"RegexSSTStateTransferFailed", "RegexSSTFailedUnknown", "RegexSSTError", "RegexSSTCancellation",
+	"RegexISTFailed", "RegexFailedToPrepareIST",
+}
+
+// IsResourceConsequence tells if the regex finds failures that should be highlighted when a resource exhaustion directly precedes them
+func IsResourceConsequence(key string) bool {
+	return utils.SliceContains(resourceConsequences, key)
+}
@@ -0,0 +1,54 @@
+package regex
+
+import (
+	"github.com/ylacancellera/galera-log-explainer/types"
+)
+
+// anchorKeys are events coming from group messages: every node logs them at the same time
+// the key identifies the event cluster-wide
+var anchorKeys = map[string]func(submatches map[string]string) string{
+	"RegexSSTComplete": func(submatches map[string]string) string {
+		return "sst " + submatches[groupNodeName] + " " + submatches[groupNodeName2]
+	},
+	"RegexInconsistencyVoteInit": func(submatches map[string]string) string {
+		return "vote " + submatches[groupSeqno]
+	},
+	"RegexInconsistencyVoteRespond": func(submatches map[string]string) string {
+		return "vote " + submatches[groupSeqno] + " " + submatches[groupNodeName]
+	},
+}
+
+// ClockAnchors lists, for each node, the events that were logged by every nodes
+// Views are taken from the contexts, the others from the timeline events
+func ClockAnchors(timeline types.Timeline) map[string][]types.ClockAnchor {
+	regexes := types.RegexMap{}
+	regexes.Merge(SSTMap).Merge(ApplicativeMap)
+	anchors := map[string][]types.ClockAnchor{}
+
+	for node, lt := range timeline {
+		nodeAnchors := []types.ClockAnchor{}
+		for _, li := range lt {
+			keyFunc, ok := anchorKeys[li.RegexUsed]
+			regex, found := regexes[li.RegexUsed]
+			if !ok || !found || li.Date == nil {
+				continue
+			}
+			submatches, ok := regex.Submatches(li.Log)
+			if !ok {
+				continue
+			}
+			nodeAnchors = append(nodeAnchors, types.ClockAnchor{Key: keyFunc(submatches), Time: li.Date.Time})
+		}
+
+		if len(lt) > 0 {
+			for _, view := range lt[len(lt)-1].Ctx.Views {
+				if view.Date == nil {
+					continue
+				}
+				nodeAnchors = append(nodeAnchors, types.ClockAnchor{Key: "view " + view.Key(), Time: view.Date.Time})
+			}
+		}
+		anchors[node] = nodeAnchors
+	}
+	return anchors
+}
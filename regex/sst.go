@@ -1,6 +1,7 @@
 package regex
 
 import (
+	"path/filepath"
 	"regexp"
 
 	"github.com/ylacancellera/galera-log-explainer/types"
@@ -22,9 +23,11 @@ var SSTMap = types.RegexMap{
 			donor := utils.ShortNodeName(submatches[groupNodeName2])
 			if utils.SliceContains(ctx.OwnNames, joiner) {
 				ctx.SST.ResyncedFromNode = donor
+				ctx.SST.ClearPhases()
 			}
 			if utils.SliceContains(ctx.OwnNames, donor) {
 				ctx.SST.ResyncingNode = joiner
+				ctx.SST.ClearPhases()
 			}
 
 			return ctx, func(ctx types.LogCtx) string {
@@ -96,6 +99,9 @@ var SSTMap = types.RegexMap{
 			ctx = addOwnNameWithSSTMetadata(ctx, "", donor)
 			return ctx, types.SimpleDisplayer(donor + utils.Paint(utils.RedText, " synced ??(node left)"))
 		},
+		// RegexSSTComplete also matches the line, but would not be able to find the joiner
+		Order:    -1,
+		Terminal: true,
 	},
 
 	"RegexSSTFailedUnknown": &types.LogRegex{
@@ -107,6 +113,9 @@ var SSTMap = types.RegexMap{
 			ctx = addOwnNameWithSSTMetadata(ctx, "", donor)
 			return ctx, types.SimpleDisplayer(donor + utils.Paint(utils.RedText, " failed to sync ??(node left)"))
 		},
+		// same as RegexSSTCompleteUnknown, with RegexSSTFailed
+		Order:    -1,
+		Terminal: true,
 	},
 
 	"RegexSSTStateTransferFailed": &types.LogRegex{
@@ -121,11 +130,16 @@ var SSTMap = types.RegexMap{
 		},
 	},
 
+	// 2023-01-01T01:01:01.000000Z 0 [ERROR] [MY-000000] [WSREP] Process completed with error: wsrep_sst_xtrabackup-v2 --role 'donor' --address '172.17.0.2:4444/xtrabackup_sst//1' [...] : 22 (Invalid argument)
 	"RegexSSTError": &types.LogRegex{
-		Regex: regexp.MustCompile("Process completed with error: wsrep_sst"),
+		Regex:         regexp.MustCompile("Process completed with error: wsrep_sst"),
+		InternalRegex: regexp.MustCompile("Process completed with error: wsrep_sst_[\\w-]+( .*: (?P<code>[0-9]+) \\((?P<reason>[^)]*)\\))?"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
-
-			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "SST error"))
+			msg := "SST error"
+			if submatches["code"] != "" {
+				msg += ", exit code " + submatches["code"] + " (" + submatches["reason"] + ")"
+			}
+			return sstFailed(ctx, log, msg)
 		},
 	},
 
@@ -142,6 +156,7 @@ var SSTMap = types.RegexMap{
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 			ctx.SetState("JOINER")
 			ctx.SST.Type = "SST"
+			ctx.SST.StartPhase(types.SSTPhaseReceiving, DateFromLog(ctx, log))
 
 			return ctx, types.SimpleDisplayer(utils.Paint(utils.YellowText, "receiving SST"))
 		},
@@ -153,6 +168,7 @@ var SSTMap = types.RegexMap{
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
 
 			ctx.SetState("DONOR")
+			ctx.SST.StartPhase(types.SSTPhaseStreaming, DateFromLog(ctx, log))
 			node := submatches[groupNodeIP]
 			if ctx.SST.ResyncingNode == "" { // we should already have something at this point
 				ctx.SST.ResyncingNode = node
@@ -249,9 +265,105 @@ var SSTMap = types.RegexMap{
 
 	// 2023-05-12T02:52:33.767132Z 0 [Note] [MY-000000] [WSREP-SST] Preparing the backup at /var/lib/mysql/sst-xb-tmpdir
 	"RegexPreparingBackup": &types.LogRegex{
-		Regex: regexp.MustCompile("Preparing the backup at"),
+		Regex:     regexp.MustCompile("Preparing the backup at"),
+		Handler:   sstPhase(types.SSTPhasePrepare, "preparing SST backup"),
+		Verbosity: types.Detailed,
+	},
+
+	// rsync donor
+	// 2023-01-01  1:01:01 0 [Note] WSREP: WSREP_SST: [INFO] Flushing tables for SST... (20230101 01:01:01.000)
+	"RegexSSTFlushingTables": &types.LogRegex{
+		Regex:     regexp.MustCompile("Flushing tables for SST"),
+		Handler:   sstPhase(types.SSTPhaseBackup, "flushing tables for SST"),
+		Verbosity: types.Detailed,
+	},
+
+	// 2023-01-01  1:01:02 0 [Note] WSREP: WSREP_SST: [INFO] Tables flushed. (20230101 01:01:02.000)
+	"RegexSSTTablesFlushed": &types.LogRegex{
+		Regex:     regexp.MustCompile("WSREP_SST: \\[INFO\\] Tables flushed"),
+		Handler:   sstPhase(types.SSTPhaseStreaming, "tables flushed, streaming SST"),
+		Verbosity: types.Detailed,
+	},
+
+	// 2023-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [WSREP-SST] ............Waiting for SST streaming to complete!
+	"RegexSSTWaitingStreaming": &types.LogRegex{
+		Regex:     regexp.MustCompile("Waiting for SST streaming to complete"),
+		Handler:   sstPhase(types.SSTPhaseReceiving, "waiting for SST streaming"),
+		Verbosity: types.Detailed,
+	},
+
+	// 2023-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [WSREP-SST] Streaming donor-keyring file before SST
+	// 2023-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [WSREP-SST] Waiting for donor keyring file
+	"RegexSSTKeyring": &types.LogRegex{
+		Regex:     regexp.MustCompile("(Streaming|Transferring|Receiving|Waiting for) (the )?(donor[ -])?keyring"),
+		Handler:   sstPhase(types.SSTPhaseKeyring, "SST keyring transfer"),
+		Verbosity: types.Detailed,
+	},
+
+	// 2023-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [WSREP-SST] Moving the backup to /var/lib/mysql/
+	"RegexSSTMovingBackup": &types.LogRegex{
+		Regex:     regexp.MustCompile("Moving the backup to"),
+		Handler:   sstPhase(types.SSTPhaseMoveBack, "moving SST backup to datadir"),
+		Verbosity: types.Detailed,
+	},
+
+	// 2023-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [WSREP-SST] Total time on joiner: 0 seconds
+	"RegexSSTTotalTime": &types.LogRegex{
+		Regex:         regexp.MustCompile("Total time on (joiner|donor)"),
+		InternalRegex: regexp.MustCompile("Total time on (?P<role>joiner|donor): (?P<seconds>[0-9]+) seconds"),
 		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
-			return ctx, types.SimpleDisplayer("preparing SST backup")
+			ctx.SST.EndPhases(DateFromLog(ctx, log))
+
+			msg := utils.Paint(utils.GreenText, "SST script done") + " in " + submatches["seconds"] + "s on " + submatches["role"]
+			if summary := ctx.SST.PhasesSummary(); summary != "" {
+				msg += " (" + summary + ")"
+			}
+			return ctx, types.SimpleDisplayer(msg)
+		},
+	},
+
+	// 2023-01-01T01:01:01.000000Z 0 [ERROR] [MY-000000] [WSREP-SST] xtrabackup finished with error: 1.  Check /var/lib/mysql//innobackup.backup.log
+	// 2023-01-01  1:01:01 0 [ERROR] WSREP: WSREP_SST: [ERROR] mariabackup finished with error: 1.  Check /var/lib/mysql//mariabackup.backup.log (20230101 01:01:01.000)
+	"RegexSSTBackupFinishedWithError": &types.LogRegex{
+		Regex:         regexp.MustCompile("(xtrabackup|innobackupex|mariabackup) finished with error"),
+		InternalRegex: regexp.MustCompile("(?P<tool>xtrabackup|innobackupex|mariabackup) finished with error: (?P<code>[0-9]+)\\.( +Check (?P<logfile>[^ ]+))?"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			msg := submatches["tool"] + " failed, exit code " + submatches["code"]
+			if submatches["logfile"] != "" {
+				msg += ", see " + filepath.Base(submatches["logfile"])
+			}
+			return sstFailed(ctx, log, msg)
+		},
+	},
+
+	// 2023-01-01T01:01:01.000000Z 0 [Note] [MY-000000] [WSREP-SST] Cleanup after exit with status:32
+	"RegexSSTCleanupExitStatus": &types.LogRegex{
+		Regex:         regexp.MustCompile("Cleanup after exit with status"),
+		InternalRegex: regexp.MustCompile("Cleanup after exit with status: ?(?P<code>[0-9]+)"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			if submatches["code"] == "0" {
+				ctx.SST.EndPhases(DateFromLog(ctx, log))
+				return ctx, nil
+			}
+			return sstFailed(ctx, log, "SST script exited with code "+submatches["code"])
+		},
+	},
+
+	// from the backup log, innobackup.backup.log or the operator's logs
+	// 2023-01-01T01:01:01.000000-00:00 0 [ERROR] [MY-011825] [Xtrabackup] failed to execute query 'LOCK INSTANCE FOR BACKUP' : 1227 (42000) Access denied
+	"RegexXtrabackupError": &types.LogRegex{
+		Regex:         regexp.MustCompile("\\[ERROR\\] \\[MY-[0-9]+\\] \\[Xtrabackup\\]"),
+		InternalRegex: regexp.MustCompile("\\[Xtrabackup\\] (?P<error>.*)"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			return ctx, types.SimpleDisplayer(utils.Paint(utils.RedText, "xtrabackup error: ") + submatches["error"])
+		},
+	},
+
+	// 2023-01-01T01:01:01.000000-00:00 0 [Note] [MY-011825] [Xtrabackup] completed OK!
+	"RegexXtrabackupCompletedOK": &types.LogRegex{
+		Regex: regexp.MustCompile("\\[Xtrabackup\\] completed OK!"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+			return ctx, types.SimpleDisplayer(utils.Paint(utils.GreenText, "xtrabackup completed OK"))
 		},
 		Verbosity: types.Detailed,
 	},
@@ -285,6 +397,29 @@ var SSTMap = types.RegexMap{
 	},
 }
 
+// sstPhase is for the lines of the SST scripts marking the start of a phase
+func sstPhase(phase, msg string) func(map[string]string, types.LogCtx, string) (types.LogCtx, types.LogDisplayer) {
+	return func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+		ctx.SST.StartPhase(phase, DateFromLog(ctx, log))
+		return ctx, types.SimpleDisplayer(msg)
+	}
+}
+
+// sstFailed tells at which step the SST script failed, and how long it took to get there
+// A failure is logged several times, durations are only given by the first one
+func sstFailed(ctx types.LogCtx, log, msg string) (types.LogCtx, types.LogDisplayer) {
+	out := utils.Paint(utils.RedText, msg)
+	date := DateFromLog(ctx, log)
+	if ctx.SST.Ongoing() {
+		phase := ctx.SST.CurrentPhase()
+		ctx.SST.Fail(date)
+		out += " during " + phase + " (" + ctx.SST.PhasesSummary() + ")"
+	} else if phase, ok := ctx.SST.FailedPhase(date); ok {
+		out += " during " + phase
+	}
+	return ctx, types.SimpleDisplayer(out)
+}
+
 func addOwnNameWithSSTMetadata(ctx types.LogCtx, joiner, donor string) types.LogCtx {
 
 	var nameToAdd string
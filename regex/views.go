@@ -89,6 +89,61 @@ var ViewsMap = types.RegexMap{
 		},
 	},
 
+	// 2023-01-06T07:05:35.698869Z 7 [Note] WSREP: New cluster view: global state: 9f191762-2542-11ee-89be-13bdb1218f0e:9339113, view# 10: Primary, number of nodes: 2, my index: 0, protocol version 3
+	// galera 3 only, newer versions have "View:" blocks instead
+	"RegexNewClusterView": &types.LogRegex{
+		Regex:         regexp.MustCompile("New cluster view:"),
+		InternalRegex: regexp.MustCompile("New cluster view: global state: " + regexViewID + ", view# (?P<viewnum>-?[0-9]+): (?P<status>[a-zA-Z-]+), number of nodes: " + regexMembers + ", my index: " + regexIdx + ", protocol version (?P<protocol>[0-9]+)"),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+
+			membercount, err := strconv.Atoi(submatches[groupMembers])
+			if err != nil {
+				return ctx, nil
+			}
+			view := types.View{
+				ID:              submatches[groupViewID],
+				Number:          submatches["viewnum"],
+				Date:            DateFromLog(ctx, log),
+				Status:          strings.ToLower(submatches["status"]),
+				ProtocolVersion: submatches["protocol"],
+				OwnIndex:        submatches[groupIdx],
+				MemberCount:     membercount,
+			}
+			ctx.Views = ctx.Views.Add(view)
+
+			return ctx, types.SimpleDisplayer("view#" + view.Number + "(" + view.Status + ", n=" + submatches[groupMembers] + ")")
+		},
+		Verbosity: types.DebugMySQL,
+	},
+
+	// 2023-06-10T04:50:46.835491Z 0 [Note] [MY-000000] [Galera] going to give up, state dump for diagnosis:
+	// evs::proto(evs::proto(6d0345f5-bcc0, GATHER, view_id(REG,02e369be-8363,1046)), GATHER) {
+	// ...
+	// known:
+	// 17a2e064 at tcp://ip:4567
+	// {o=0,s=1,i=0,fs=-1,}
+	// ...
+	// full samples at the end of this file
+	"RegexEVSStateDump": &types.LogRegex{
+		Regex: regexp.MustCompile(`state dump for diagnosis:(\n|\\n)evs::proto`),
+		Handler: func(submatches map[string]string, ctx types.LogCtx, log string) (types.LogCtx, types.LogDisplayer) {
+
+			dump, ok := parseEVSDump(log)
+			if !ok {
+				return ctx, nil
+			}
+			for _, node := range dump.Known {
+				if ip := nodeIPRegex.FindStringSubmatch(node.Address); ip != nil {
+					ctx.HashToIP[node.UUID] = ip[nodeIPRegex.SubexpIndex(groupNodeIP)]
+				}
+			}
+			return ctx, func(ctx types.LogCtx) string {
+				return utils.Paint(utils.RedText, "gave up") + "(" + dump.State + "): " + dump.Matrix(ctx)
+			}
+		},
+		MultiLine: true,
+	},
+
 	"RegexNodeSuspect": &types.LogRegex{
 		Regex:         regexp.MustCompile("suspecting node"),
 		InternalRegex: regexp.MustCompile("suspecting node: " + regexNodeHash),
@@ -182,9 +237,121 @@ var ViewsMap = types.RegexMap{
 	},
 }
 
-/*
+var (
+	viewIDRegex       = regexp.MustCompile(`View:\n\s+id: ` + regexViewID)
+	viewStatusRegex   = regexp.MustCompile(`(?m)^\s*status: (?P<status>[a-z-]+)`)
+	viewProtocolRegex = regexp.MustCompile(`(?m)^\s*protocol_version: (?P<protocol>-?[0-9]+)`)
+	viewOwnIdxRegex   = regexp.MustCompile(`(?m)^\s*own_index: ` + regexIdx)
+	viewMemberRegex   = regexp.MustCompile(`(?m)^\s*` + regexIdx + ": " + regexUUID + ", " + regexNodeName + `\s*$`)
+)
+
+var (
+	evsHeaderRegex = regexp.MustCompile(`evs::proto\(evs::proto\((?P<self>[a-z0-9-]+), (?P<state>[A-Z_]+), (?P<view>view_id\([A-Z]+,[a-z0-9-]+,[0-9]+\))`)
+	evsKnownRegex  = regexp.MustCompile(`^(?P<uuid>[a-z0-9]{8}(-[a-z0-9]{4})?) at ?(?P<address>\S*)`)
+	evsFlagsRegex  = regexp.MustCompile(`^\{o=(?P<o>[01]),s=(?P<s>[01]),i=(?P<i>[01]),fs=(?P<fs>-?[0-9]+)`)
+	evsPeerRegex   = regexp.MustCompile(`^\s*(?P<uuid>[a-z0-9]{8}(-[a-z0-9]{4})?), \{o=(?P<o>[01]),s=(?P<s>[01]),e=[01],ls=-?[0-9]+,vid=(?P<view>view_id\([A-Z]+,[a-z0-9-]+,[0-9]+\)),ss=(?P<ss>-?[0-9]+)`)
+	nodeIPRegex    = regexp.MustCompile(regexNodeIPMethod)
+
+	// k8s logs keep the dump in json strings, with escaped newlines
+	k8sUnescaper = strings.NewReplacer(`\n`, "\n", `\t`, "\t")
+)
+
+// parseEVSDump reads the "known:" part of an evs state dump
+// Each known node has its flags from the dumping node perspective, and the content of its last join message
+// which tells how it sees every other nodes
+func parseEVSDump(log string) (types.EVSDump, bool) {
+	log = k8sUnescaper.Replace(log)
+
+	header := evsHeaderRegex.FindStringSubmatch(log)
+	if header == nil {
+		return types.EVSDump{}, false
+	}
+	dump := types.EVSDump{
+		Self:   header[evsHeaderRegex.SubexpIndex("self")],
+		State:  header[evsHeaderRegex.SubexpIndex("state")],
+		ViewID: header[evsHeaderRegex.SubexpIndex("view")],
+	}
+
+	inKnown := false
+	for _, line := range strings.Split(log, "\n") {
+		line = strings.TrimPrefix(line, `{"log":"`)
+		if strings.TrimSpace(line) == "known:" {
+			inKnown = true
+			continue
+		}
+		if !inKnown {
+			continue
+		}
+
+		if m := evsKnownRegex.FindStringSubmatch(line); m != nil {
+			dump.Known = append(dump.Known, types.EVSNode{
+				UUID:    m[evsKnownRegex.SubexpIndex("uuid")],
+				Address: m[evsKnownRegex.SubexpIndex("address")],
+			})
+			continue
+		}
+		if len(dump.Known) == 0 {
+			continue
+		}
+		node := &dump.Known[len(dump.Known)-1]
+
+		if m := evsFlagsRegex.FindStringSubmatch(line); m != nil {
+			node.Operational = m[evsFlagsRegex.SubexpIndex("o")] == "1"
+			node.Suspected = m[evsFlagsRegex.SubexpIndex("s")] == "1"
+			node.Installed = m[evsFlagsRegex.SubexpIndex("i")] == "1"
+			node.FifoSeq = m[evsFlagsRegex.SubexpIndex("fs")]
+			continue
+		}
+		if m := evsPeerRegex.FindStringSubmatch(line); m != nil {
+			node.Sees = append(node.Sees, types.EVSPeer{
+				UUID:        m[evsPeerRegex.SubexpIndex("uuid")],
+				Operational: m[evsPeerRegex.SubexpIndex("o")] == "1",
+				Suspected:   m[evsPeerRegex.SubexpIndex("s")] == "1",
+				ViewID:      m[evsPeerRegex.SubexpIndex("view")],
+				SafeSeq:     m[evsPeerRegex.SubexpIndex("ss")],
+			})
+		}
+	}
+	return dump, len(dump.Known) > 0
+}
+
+// parseViewBlock reads a whole "View:" record
+//
+//	View:
+//	  id: 9f191762-2542-11ee-89be-13bdb1218f0e:9339113
+//	  status: primary
+//	  protocol_version: 4
+//	  capabilities: MULTI-MASTER, CERTIFICATION, ...
+//	  final: no
+//	  own_index: 1
+//	  members(2):
+//	        0: 015702fc-32f5-11ed-a4ca-267f97316394, node1
+//	        1: 08dd5580-32f7-11ed-a9eb-af5e3d01519e, node2
+func parseViewBlock(log string) types.View {
+	view := types.View{}
+	if m := viewIDRegex.FindStringSubmatch(log); m != nil {
+		view.ID = m[1]
+	}
+	if m := viewStatusRegex.FindStringSubmatch(log); m != nil {
+		view.Status = m[1]
+	}
+	if m := viewProtocolRegex.FindStringSubmatch(log); m != nil {
+		view.ProtocolVersion = m[1]
+	}
+	if m := viewOwnIdxRegex.FindStringSubmatch(log); m != nil {
+		view.OwnIndex = m[1]
+	}
+	for _, m := range viewMemberRegex.FindAllStringSubmatch(log, -1) {
+		view.Members = append(view.Members, types.ViewMember{
+			UUID: m[viewMemberRegex.SubexpIndex(groupUUID)],
+			Name: m[viewMemberRegex.SubexpIndex(groupNodeName)],
+		})
+	}
+	view.MemberCount = len(view.Members)
+	return view
+}
 
-2022-11-29T23:34:51.820009-05:00 0 [Warning] [MY-000000] [Galera] Could not find peer: c0ff4085-5ad7-11ed-8b74-cfeec74147fe
+/*
 
 2022-12-07  1:00:06 0 [Note] WSREP: Member 0.0 (node) synced with group.
 
@@ -194,9 +361,6 @@ var ViewsMap = types.RegexMap{
 
 
 
-2021-04-22T08:01:05.000581Z 0 [Warning] WSREP: Failed to report last committed 66328091, -110 (Connection timed out)
-
-
 input_map=evs::input_map: {aru_seq=8,safe_seq=8,node_index=node: {idx=0,range=[9,8],safe_seq=8} node: {idx=1,range=[9,8],safe_seq=8} },
 fifo_seq=4829086170,
 last_sent=8,
@@ -240,18 +404,7 @@ d4397932 at tcp://ip:4567
 {o=0,s=1,i=0,fs=4685894552,}
  }
 
- Transport endpoint is not connected
-
-
- 2023-03-31T08:05:57.964535Z 0 [Note] WSREP: handshake failed, my group: '<group>', peer group: '<bad group>'
-
- 2023-04-04T22:35:23.487304Z 0 [Warning] [MY-000000] [Galera] Handshake failed: tlsv1 alert decrypt error
-
- 2023-04-16T19:35:06.875877Z 0 [Warning] [MY-000000] [Galera] Action message in non-primary configuration from member 0
-
 {"log":"2023-06-10T04:50:46.835491Z 0 [Note] [MY-000000] [Galera] going to give up, state dump for diagnosis:\nevs::proto(evs::proto(6d0345f5-bcc0, GATHER, view_id(REG,02e369be-8363,1046)), GATHER) {\ncurrent_view=Current view of cluster as seen by this node\nview (view_id(REG,02e369be-8363,1046)\nmemb {\n\t02e369be-8363,0\n\t49761f3d-bd34,0\n\t6d0345f5-bcc0,0\n\tb05443d1-96bf,0\n\tb05443d1-96c0,0\n\t}\njoined {\n\t}\nleft {\n\t}\npartitioned {\n\t}\n),\ninput_map=evs::input_map: {aru_seq=461,safe_seq=461,node_index=node: {idx=0,range=[462,461],safe_seq=461} node: {idx=1,range=[462,461],safe_seq=461} node: {idx=2,range=[462,461],safe_seq=461} node: {idx=3,range=[462,461],safe_seq=461} node: {idx=4,range=[462,461],safe_seq=461} },\nfifo_seq=221418422,\nlast_sent=461,\nknown:\n","file":"/var/lib/mysql/mysqld-error.log"}
 
 
-[Warning] WSREP: FLOW message from member -12921743687968 in non-primary configuration. Ignored.
-
 */
@@ -3,13 +3,20 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
 )
 
 type regexList struct {
-	Json bool
+	Json     bool
+	Stats    bool     `help:"Search the given logs and report how often each regexes matched, per file and per node" xor:"report"`
+	Overlaps bool     `help:"Search the given logs and report lines where several regexes displayed something" xor:"report"`
+	Paths    []string `arg:"" optional:"" name:"paths" help:"paths of the log to use with --stats or --overlaps"`
 }
 
 func (l *regexList) Help() string {
@@ -21,6 +28,13 @@ func (l *regexList) Run() error {
 	allregexes := regex.AllRegexes()
 	allregexes.Merge(regex.PXCOperatorMap)
 
+	if l.Stats || l.Overlaps {
+		return l.runReport(allregexes)
+	}
+	if len(l.Paths) > 0 {
+		return errors.New("paths are only used with --stats or --overlaps")
+	}
+
 	if l.Json {
 		out, err := json.Marshal(&allregexes)
 		if err != nil {
@@ -37,3 +51,104 @@ func (l *regexList) Run() error {
 	fmt.Println(keys)
 	return nil
 }
+
+// runReport searches with every regexes: the point is to see which ones are useless, too loose, or duplicated
+func (l *regexList) runReport(allregexes types.RegexMap) error {
+	if len(l.Paths) == 0 {
+		return errors.New("--stats and --overlaps need paths of logs to search")
+	}
+	if !CLI.PxcOperator {
+		for key := range regex.PXCOperatorMap {
+			delete(allregexes, key)
+		}
+	}
+
+	// regexes superseded by MultiLine ones are not searched by the native engine, they should not be reported as never matched
+	searched := allregexes
+	if CLI.Engine != "grep" {
+		searched = nativeRegexes(allregexes)
+	}
+
+	regexStats = newRegexStatsCollector()
+	defer func() { regexStats = nil }()
+	if _, err := timelineFromPaths(l.Paths, searched); err != nil {
+		return err
+	}
+
+	if l.Overlaps {
+		return l.printOverlaps(regexStats.overlaps().Sorted())
+	}
+	return l.printStats(searched)
+}
+
+func (l *regexList) printOverlaps(overlaps []types.RegexOverlap) error {
+	if l.Json {
+		out, err := json.Marshal(overlaps)
+		if err != nil {
+			return errors.Wrap(err, "could not marshal regex overlaps")
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	if len(overlaps) == 0 {
+		fmt.Println("no line was displayed by several regexes")
+	}
+	for _, overlap := range overlaps {
+		fmt.Println(utils.Paint(utils.YellowText, strings.Join(overlap.Keys, ", ")) + fmt.Sprintf(": %d lines", overlap.Count))
+		fmt.Println("	sample: " + overlap.Sample)
+	}
+	return nil
+}
+
+func (l *regexList) printStats(searched types.RegexMap) error {
+	keys := searched.SortedKeys()
+	summaries := types.SummarizeRegexStats(keys, regexStats.files())
+
+	if l.Json {
+		out, err := json.Marshal(summaries)
+		if err != nil {
+			return errors.Wrap(err, "could not marshal regex stats")
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	for _, summary := range summaries {
+		if summary.NeverUsed {
+			fmt.Println(utils.Paint(utils.RedText, summary.Key) + ": never matched")
+			continue
+		}
+		fmt.Println(utils.Paint(utils.BlueText, summary.Key) + ": " + formatRegexStat(summary.Total))
+		for _, node := range sortedStatKeys(summary.PerNode) {
+			fmt.Println("\tnode " + node + ": " + formatRegexStat(summary.PerNode[node]))
+		}
+		for _, path := range sortedStatKeys(summary.PerFile) {
+			fmt.Println("\tfile " + path + ": " + formatRegexStat(summary.PerFile[path]))
+		}
+	}
+	return nil
+}
+
+func formatRegexStat(stat types.RegexStat) string {
+	s := fmt.Sprintf("%d matches", stat.Matches)
+	if stat.NilDisplayers > 0 {
+		s += fmt.Sprintf(", %d without display", stat.NilDisplayers)
+	}
+	if stat.InternalMisses > 0 {
+		s += ", " + utils.Paint(utils.YellowText, fmt.Sprintf("%d not matching the internal regex", stat.InternalMisses))
+	}
+	if stat.First != nil && stat.Last != nil {
+		s += ", from " + stat.First.DisplayTime + " to " + stat.Last.DisplayTime
+	}
+	return s
+}
+
+func sortedStatKeys(m map[string]types.RegexStat) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/ylacancellera/galera-log-explainer/types"
+)
+
+// regexStats collects how every regexes behaved, for regex-list --stats and --overlaps
+// It is nil for every other commands
+var regexStats *regexStatsCollector
+
+type regexStatsCollector struct {
+	sync.Mutex
+	processors map[string]*lineProcessor
+	order      []string
+}
+
+func newRegexStatsCollector() *regexStatsCollector {
+	return &regexStatsCollector{processors: map[string]*lineProcessor{}}
+}
+
+// setOrder gives the paths in the order they were given, so that reports do not depend on which worker finished first
+func (c *regexStatsCollector) setOrder(paths []string) {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.order = append([]string{}, paths...)
+}
+
+// track enables stats on the processor of the file
+func (c *regexStatsCollector) track(path string, p *lineProcessor) {
+	if c == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	p.stats = map[string]*types.RegexStat{}
+	p.overlaps = types.RegexOverlaps{}
+	c.processors[path] = p
+}
+
+// files gives the stats of each file, in the order they were given
+func (c *regexStatsCollector) files() []types.FileRegexStats {
+	c.Lock()
+	defer c.Unlock()
+	files := []types.FileRegexStats{}
+	for _, path := range c.order {
+		p, ok := c.processors[path]
+		if !ok {
+			continue
+		}
+		files = append(files, types.FileRegexStats{Path: path, Node: p.node(), Regexes: p.stats})
+	}
+	return files
+}
+
+func (p *lineProcessor) addStat(key string, regex *types.LogRegex, date *types.Date, line string, displayer types.LogDisplayer) {
+	stat, ok := p.stats[key]
+	if !ok {
+		stat = &types.RegexStat{}
+		p.stats[key] = stat
+	}
+	internalMiss := regex.InternalRegex != nil && !regex.InternalRegex.MatchString(line)
+	stat.Add(date, displayer, internalMiss)
+}
+
+// overlaps gives every regexes that displayed something on the same lines, from every files
+func (c *regexStatsCollector) overlaps() types.RegexOverlaps {
+	c.Lock()
+	defer c.Unlock()
+	overlaps := types.RegexOverlaps{}
+	for _, path := range c.order {
+		p, ok := c.processors[path]
+		if !ok {
+			continue
+		}
+		overlaps.Merge(p.overlaps)
+	}
+	return overlaps
+}
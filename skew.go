@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+	"gopkg.in/yaml.v2"
+)
+
+type skew struct {
+	Paths []string `arg:"" name:"paths" help:"paths of the log to use"`
+	Yaml  bool     `xor:"format"`
+	Json  bool     `xor:"format"`
+}
+
+func (s *skew) Help() string {
+	return `Estimate how much the clock of each node is ahead of the others
+	Galera logs some events on every nodes at the same time (views, SST completions, inconsistency votes), they are compared between nodes
+
+Usage:
+	galera-log-explainer skew *.log
+	galera-log-explainer list --all --correct-skew *.log
+	`
+}
+
+func (s *skew) Run() error {
+
+	regexes := regex.IdentsMap.Merge(regex.ViewsMap).Merge(regex.SSTMap).Merge(regex.ApplicativeMap)
+	timeline, err := timelineFromPaths(s.Paths, regexes)
+	if err != nil {
+		return err
+	}
+
+	skews := types.EstimateClockSkews(regex.ClockAnchors(timeline))
+
+	var out string
+	if s.Yaml {
+		tmp, err := yaml.Marshal(skews)
+		if err != nil {
+			return err
+		}
+		out = string(tmp)
+	} else if s.Json {
+		tmp, err := json.Marshal(skews)
+		if err != nil {
+			return err
+		}
+		out = string(tmp)
+	} else {
+		out = displaySkews(timeline, skews)
+	}
+	fmt.Println(out)
+	return nil
+}
+
+func displaySkews(timeline types.Timeline, skews []types.ClockSkew) string {
+	if len(skews) == 0 {
+		return "no events shared between nodes, clock skew can't be estimated"
+	}
+
+	out := utils.Paint(utils.BlueText, "reference: ") + skews[0].Reference
+	estimated := map[string]bool{}
+	for _, skew := range skews {
+		estimated[skew.Node] = true
+		if skew.Node == skew.Reference {
+			continue
+		}
+		out += "\n" + skew.Node + ": " + utils.Paint(utils.YellowText, types.SignedDuration(skew.Offset)) + " (" + strconv.Itoa(skew.Anchors) + " shared events)"
+	}
+
+	nodes := []string{}
+	for node := range timeline {
+		if !estimated[node] {
+			nodes = append(nodes, node)
+		}
+	}
+	sort.Strings(nodes)
+	for _, node := range nodes {
+		out += "\n" + node + ": " + utils.Paint(utils.RedText, "no shared events")
+	}
+	return out
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ylacancellera/galera-log-explainer/types"
+)
+
+type tzOverride struct {
+	glob     string
+	location *time.Location
+}
+
+var (
+	defaultLocation *time.Location
+	tzOverrides     []tzOverride
+)
+
+// setupTimezones validates every timezones given in parameters
+func setupTimezones() error {
+	var err error
+	defaultLocation, err = time.LoadLocation(CLI.Timezone)
+	if err != nil {
+		return errors.Wrap(err, "invalid --timezone")
+	}
+
+	tzOverrides = []tzOverride{}
+	for _, tz := range CLI.Tz {
+		glob, zone, ok := strings.Cut(tz, "=")
+		if !ok || glob == "" {
+			return errors.Errorf("invalid --tz %s, expected GLOB=TIMEZONE", tz)
+		}
+		if _, err := filepath.Match(glob, ""); err != nil {
+			return errors.Wrapf(err, "invalid --tz glob %s", glob)
+		}
+		location, err := time.LoadLocation(zone)
+		if err != nil {
+			return errors.Wrapf(err, "invalid --tz %s", tz)
+		}
+		tzOverrides = append(tzOverrides, tzOverride{glob: glob, location: location})
+	}
+
+	if CLI.DisplayTimezone != "" {
+		types.DisplayLocation, err = time.LoadLocation(CLI.DisplayTimezone)
+		if err != nil {
+			return errors.Wrap(err, "invalid --display-timezone")
+		}
+	}
+	types.DisplayLayout = CLI.DisplayLayout
+	return nil
+}
+
+// locationForPath gives the timezone of the dates of a file that do not have any
+func locationForPath(path string) *time.Location {
+	for _, override := range tzOverrides {
+		if matchPathGlob(override.glob, path) {
+			return override.location
+		}
+	}
+	return defaultLocation
+}
+
+// matchPathGlob matches the glob against the end of the path, so that "node3/*.log" works whatever the base directory
+// archive members ("bundle.tar.gz!/node3/mysqld.log") are matched the same way
+func matchPathGlob(glob, path string) bool {
+	path = filepath.ToSlash(path)
+	glob = filepath.ToSlash(glob)
+	if ok, _ := filepath.Match(glob, path); ok {
+		return true
+	}
+	globParts := strings.Split(glob, "/")
+	pathParts := strings.Split(path, "/")
+	if len(pathParts) < len(globParts) {
+		return false
+	}
+	ok, _ := filepath.Match(glob, strings.Join(pathParts[len(pathParts)-len(globParts):], "/"))
+	return ok
+}
@@ -0,0 +1,128 @@
+package types
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+type CertificationConflictKind string
+
+const (
+	CertificationFailure CertificationConflictKind = "certification failure"
+	BFAbort              CertificationConflictKind = "brute force abort"
+	MDLBFBFConflict      CertificationConflictKind = "MDL BF-BF conflict"
+	ReplayFailure        CertificationConflictKind = "replay failure"
+)
+
+// CertificationConflict is a transaction that lost against another one
+// every node certifies every transaction, so the same conflict can be found in each of their logs
+type CertificationConflict struct {
+	Kind         CertificationConflictKind `json:"kind"`
+	Date         *Date                     `json:"date,omitempty"`
+	ConnID       string                    `json:"connID,omitempty"`
+	TrxID        string                    `json:"trxID,omitempty"`
+	Seqno        string                    `json:"seqno,omitempty"`
+	Source       string                    `json:"source,omitempty"`       // uuid of the node the losing transaction comes from
+	WinnerSource string                    `json:"winnerSource,omitempty"` // uuid of the node the winning transaction comes from
+}
+
+type CertificationConflicts []CertificationConflict
+
+// Add ignores a conflict about the same transaction as the previous one, as some are logged on several lines
+func (cs CertificationConflicts) Add(c CertificationConflict) CertificationConflicts {
+	if len(cs) > 0 && c.TrxID != "" && cs[len(cs)-1].sameTrx(c) {
+		return cs
+	}
+	return append(cs, c)
+}
+
+func (c CertificationConflict) sameTrx(c2 CertificationConflict) bool {
+	return c.Kind == c2.Kind && c.Source == c2.Source && c.ConnID == c2.ConnID && c.TrxID == c2.TrxID
+}
+
+func (cs CertificationConflicts) CountByKind() map[CertificationConflictKind]int {
+	counts := map[CertificationConflictKind]int{}
+	for _, c := range cs {
+		counts[c.Kind]++
+	}
+	return counts
+}
+
+// CertificationReport aggregates the conflicts found in every nodes
+type CertificationReport struct {
+	PerNode    map[string]map[CertificationConflictKind]int `json:"perNode"` // as logged by each node
+	Losers     []CertificationCount                         `json:"losers"`  // whose writes lose most often
+	HotPeriods []CertificationCount                         `json:"hotPeriods"`
+}
+
+// CertificationCount is a number of conflicts for a node, or a period
+type CertificationCount struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// NewCertificationReport deduplicates the conflicts found by several nodes, and aggregates them
+// hot periods are the "top" periods having the most conflicts
+func NewCertificationReport(ctxs map[string]LogCtx, period time.Duration, top int) CertificationReport {
+	report := CertificationReport{PerNode: map[string]map[CertificationConflictKind]int{}}
+	losers := map[string]int{}
+	periods := map[string]int{}
+	seen := map[CertificationConflict]bool{}
+
+	nodes := []string{}
+	for node := range ctxs {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	for _, node := range nodes {
+		ctx := ctxs[node]
+		if len(ctx.CertificationConflicts) == 0 {
+			continue
+		}
+		report.PerNode[node] = ctx.CertificationConflicts.CountByKind()
+
+		for _, c := range ctx.CertificationConflicts {
+			loser := node
+			if c.Source != "" {
+				id := CertificationConflict{Kind: c.Kind, Source: c.Source, ConnID: c.ConnID, TrxID: c.TrxID, Seqno: c.Seqno}
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+				loser = DisplayHashSimplestForm(ctx, utils.UUIDToShortUUID(c.Source))
+			}
+			// both transactions were replicated ones, none of them lost
+			if c.Kind != MDLBFBFConflict {
+				losers[loser]++
+			}
+			if c.Date != nil {
+				periods[c.Date.Time.Truncate(period).Format(c.Date.Layout)]++
+			}
+		}
+	}
+
+	report.Losers = sortedCounts(losers, 0)
+	report.HotPeriods = sortedCounts(periods, top)
+	return report
+}
+
+// sortedCounts gives the highest counts first, limited to "top" when it is not 0
+func sortedCounts(counts map[string]int, top int) []CertificationCount {
+	sorted := []CertificationCount{}
+	for key, count := range counts {
+		sorted = append(sorted, CertificationCount{Key: key, Count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Key < sorted[j].Key
+	})
+	if top > 0 && len(sorted) > top {
+		sorted = sorted[:top]
+	}
+	return sorted
+}
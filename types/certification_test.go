@@ -0,0 +1,59 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewCertificationReport(t *testing.T) {
+	date := func(min, sec int) *Date {
+		return NewDate(time.Date(2023, 1, 1, 1, min, sec, 0, time.UTC), "2006-01-02T15:04:05.000000Z")
+	}
+	lost := CertificationConflict{Kind: CertificationFailure, Source: "fb9d6310-ee8b-11ed-8aee-f7542ad73e53", ConnID: "48", TrxID: "2696", Seqno: "21", Date: date(1, 1)}
+	lostOnNode2 := lost
+	lostOnNode2.Date = date(1, 2)
+
+	ctxs := map[string]LogCtx{
+		"node1": {
+			HashToNodeName:         map[string]string{"fb9d6310-8aee": "node3"},
+			CertificationConflicts: CertificationConflicts{lost, {Kind: BFAbort, Date: date(1, 30)}},
+		},
+		"node2": {
+			HashToNodeName:         map[string]string{"fb9d6310-8aee": "node3"},
+			CertificationConflicts: CertificationConflicts{lostOnNode2, {Kind: MDLBFBFConflict, Date: date(5, 0)}},
+		},
+		"node3": {},
+	}
+
+	report := NewCertificationReport(ctxs, time.Minute, 1)
+
+	expectedPerNode := map[string]map[CertificationConflictKind]int{
+		"node1": {CertificationFailure: 1, BFAbort: 1},
+		"node2": {CertificationFailure: 1, MDLBFBFConflict: 1},
+	}
+	if !reflect.DeepEqual(report.PerNode, expectedPerNode) {
+		t.Errorf("expected %v, got %v", expectedPerNode, report.PerNode)
+	}
+	// the conflict logged by both nodes is counted once, MDL BF-BF conflicts have no loser
+	expectedLosers := []CertificationCount{{Key: "node1", Count: 1}, {Key: "node3", Count: 1}}
+	if !reflect.DeepEqual(report.Losers, expectedLosers) {
+		t.Errorf("expected %v, got %v", expectedLosers, report.Losers)
+	}
+	expectedPeriods := []CertificationCount{{Key: "2023-01-01T01:01:00.000000Z", Count: 2}}
+	if !reflect.DeepEqual(report.HotPeriods, expectedPeriods) {
+		t.Errorf("expected %v, got %v", expectedPeriods, report.HotPeriods)
+	}
+}
+
+func TestCertificationConflictsAdd(t *testing.T) {
+	replay := CertificationConflict{Kind: ReplayFailure, Source: "fb9d6310-ee8b-11ed-8aee-f7542ad73e53", ConnID: "48", TrxID: "2696"}
+	cs := CertificationConflicts{}.Add(replay).Add(replay)
+	if len(cs) != 1 {
+		t.Errorf("the same transaction logged twice should be added once, got %d", len(cs))
+	}
+	cs = cs.Add(CertificationConflict{Kind: BFAbort}).Add(CertificationConflict{Kind: BFAbort})
+	if len(cs) != 3 {
+		t.Errorf("conflicts without transaction ids can't be deduplicated, expected 3, got %d", len(cs))
+	}
+}
@@ -3,15 +3,17 @@ package types
 type Conflicts []*Conflict
 
 type Conflict struct {
-	Seqno       string
+	Seqno       string // empty until galera logs it, after the applier error
 	InitiatedBy []string
 	Winner      string // winner will help the winning md5sum
 	VotePerNode map[string]ConflictVote
+	Table       string // only known from applier errors, eg: "test.t1"
 }
 
 type ConflictVote struct {
-	MD5   string
-	Error string
+	MD5       string
+	Error     string
+	ErrorCode string // only known from applier errors, eg: "1032"
 }
 
 func (cs Conflicts) Merge(c Conflict) Conflicts {
@@ -24,9 +26,49 @@ func (cs Conflicts) Merge(c Conflict) Conflicts {
 		}
 	}
 
+	// the applier error was logged just before, without seqno
+	// the vote replaces it, the node could have been named differently before
+	if pending := cs.Pending(); pending != nil && c.Seqno != "" {
+		errorCode := ""
+		for _, vote := range pending.VotePerNode {
+			errorCode = vote.ErrorCode
+		}
+		for node, vote := range c.VotePerNode {
+			if vote.ErrorCode == "" {
+				vote.ErrorCode = errorCode
+			}
+			c.VotePerNode[node] = vote
+		}
+		c.Table = pending.Table
+		*pending = c
+		return cs
+	}
+
 	return append(cs, &c)
 }
 
+// Pending is the latest conflict found from an applier error, still waiting for its seqno
+func (cs Conflicts) Pending() *Conflict {
+	if len(cs) > 0 && cs[len(cs)-1].Seqno == "" {
+		return cs[len(cs)-1]
+	}
+	return nil
+}
+
+// IsVoted tells if the conflict was resolved by a consistency vote (Galera 4)
+// Galera 3 nodes only know their own applier error
+func (c Conflict) IsVoted() bool {
+	if c.Winner != "" {
+		return true
+	}
+	for _, vote := range c.VotePerNode {
+		if vote.MD5 != "" {
+			return true
+		}
+	}
+	return false
+}
+
 func (cs Conflicts) ConflictWithSeqno(seqno string) *Conflict {
 	// technically could make it a binary search, seqno should be ever increasing
 	for _, c := range cs {
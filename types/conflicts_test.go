@@ -0,0 +1,27 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConflictsMergePending(t *testing.T) {
+	// the applier error was found before the node name was known
+	cs := Conflicts{&Conflict{InitiatedBy: []string{"node1.log"}, Table: "test.t1", VotePerNode: map[string]ConflictVote{"node1.log": {Error: "Duplicate entry", ErrorCode: "1062"}}}}
+
+	cs = cs.Merge(Conflict{Seqno: "20", InitiatedBy: []string{"node1"}, VotePerNode: map[string]ConflictVote{"node1": {MD5: "bdb2b9234ae75cb3", Error: "Duplicate entry"}}})
+
+	expected := Conflicts{&Conflict{Seqno: "20", InitiatedBy: []string{"node1"}, Table: "test.t1", VotePerNode: map[string]ConflictVote{"node1": {MD5: "bdb2b9234ae75cb3", Error: "Duplicate entry", ErrorCode: "1062"}}}}
+	if !reflect.DeepEqual(cs, expected) {
+		t.Errorf("expected %+v, got %+v", *expected[0], *cs[0])
+	}
+	if !cs[0].IsVoted() {
+		t.Errorf("expected conflict to be voted")
+	}
+
+	// not pending anymore
+	cs = cs.Merge(Conflict{Seqno: "21", VotePerNode: map[string]ConflictVote{}})
+	if len(cs) != 2 {
+		t.Errorf("expected 2 conflicts, got %d", len(cs))
+	}
+}
@@ -2,6 +2,9 @@ package types
 
 import (
 	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
 
 	"github.com/ylacancellera/galera-log-explainer/utils"
 )
@@ -15,6 +18,7 @@ type LogCtx struct {
 	OwnIPs                 []string
 	OwnHashes              []string
 	OwnNames               []string
+	Hostname               string // given by the log source (syslog, journald), usually unknown
 	stateErrorLog          string
 	stateRecoveryLog       string
 	statePostProcessingLog string
@@ -23,6 +27,7 @@ type LogCtx struct {
 	SST                    SST
 	MyIdx                  string
 	MemberCount            int
+	Views                  Views
 	Desynced               bool
 	HashToIP               map[string]string
 	HashToNodeName         map[string]string
@@ -31,10 +36,23 @@ type LogCtx struct {
 	IPToNodeName           map[string]string
 	minVerbosity           Verbosity
 	Conflicts              Conflicts
+	ResourceExhaustion     *ResourceExhaustion     // nil when the node is not known to be starved of anything
+	NetworkIssues          map[string]NetworkIssue // keyed by peer ip, or hash when the ip is unknown
+	FlowControl            FlowControl
+	CertificationConflicts CertificationConflicts
+	LargeTransactions      LargeTransactions
+	OngoingDDLs            map[string]DDL
+
+	// dates without years (syslog, journald) are completed using it, usually the file modification time
+	YearReference time.Time
+	// timezone of dates that do not have any, nil means UTC
+	Location *time.Location
+	// how much the node clock was ahead of the others, when it was corrected
+	ClockSkew time.Duration
 }
 
 func NewLogCtx() LogCtx {
-	return LogCtx{minVerbosity: Debug, HashToIP: map[string]string{}, IPToHostname: map[string]string{}, IPToMethod: map[string]string{}, IPToNodeName: map[string]string{}, HashToNodeName: map[string]string{}}
+	return LogCtx{minVerbosity: Debug, HashToIP: map[string]string{}, IPToHostname: map[string]string{}, IPToMethod: map[string]string{}, IPToNodeName: map[string]string{}, HashToNodeName: map[string]string{}, NetworkIssues: map[string]NetworkIssue{}, OngoingDDLs: map[string]DDL{}}
 }
 
 // State will return the wsrep state of the current file type
@@ -90,6 +108,9 @@ func (ctx *LogCtx) IsPrimary() bool {
 }
 
 func (ctx *LogCtx) OwnHostname() string {
+	if ctx.Hostname != "" {
+		return ctx.Hostname
+	}
 	for _, ip := range ctx.OwnIPs {
 		if hn, ok := ctx.IPToHostname[ip]; ok {
 			return hn
@@ -188,6 +209,9 @@ func (ctx *LogCtx) AddOwnIP(ip string) {
 		return
 	}
 	ctx.OwnIPs = append(ctx.OwnIPs, ip)
+	if ctx.Hostname != "" {
+		ctx.IPToHostname[ip] = ctx.Hostname
+	}
 	for _, hash := range ctx.OwnHashes {
 		ctx.HashToIP[hash] = ip
 	}
@@ -196,6 +220,19 @@ func (ctx *LogCtx) AddOwnIP(ip string) {
 	}
 }
 
+// SetHostname stores the hostname given by the log source
+// It is not a node name: it is only displayed when galera did not give any, see Identifier
+func (ctx *LogCtx) SetHostname(hostname string) {
+	// meaningless, every hosts have it
+	if hostname == "localhost" || hostname == ctx.Hostname {
+		return
+	}
+	ctx.Hostname = hostname
+	for _, ip := range ctx.OwnIPs {
+		ctx.IPToHostname[ip] = hostname
+	}
+}
+
 // MergeMapsWith will take a slice of contexts and merge every translation maps
 // into the base context. It won't touch "local" infos such as "ownNames"
 func (base *LogCtx) MergeMapsWith(ctxs []LogCtx) {
@@ -230,38 +267,59 @@ func (base *LogCtx) Inherit(ctx LogCtx) {
 	if base.Version == "" {
 		base.Version = ctx.Version
 	}
+	if base.Hostname == "" {
+		base.Hostname = ctx.Hostname
+	}
 	base.Conflicts = append(ctx.Conflicts, base.Conflicts...)
+	base.Views = append(ctx.Views, base.Views...)
+	base.FlowControl.Inherit(ctx.FlowControl)
+	base.CertificationConflicts = append(ctx.CertificationConflicts, base.CertificationConflicts...)
+	base.LargeTransactions.Inherit(ctx.LargeTransactions)
+	base.inheritNetworkIssues(ctx.NetworkIssues)
 	base.MergeMapsWith([]LogCtx{ctx})
 }
 
+// logCtxJSON is how contexts are dumped by the ctx subcommand, and loaded back by explain-line
+type logCtxJSON struct {
+	FilePath               string
+	FileType               string
+	OwnIPs                 []string
+	OwnHashes              []string
+	OwnNames               []string
+	Hostname               string
+	StateErrorLog          string
+	StateRecoveryLog       string
+	StatePostProcessingLog string
+	StateBackupLog         string
+	Version                string
+	SST                    SST
+	MyIdx                  string
+	MemberCount            int
+	Views                  Views
+	Desynced               bool
+	HashToIP               map[string]string
+	HashToNodeName         map[string]string
+	IPToHostname           map[string]string
+	IPToMethod             map[string]string
+	IPToNodeName           map[string]string
+	MinVerbosity           Verbosity
+	Conflicts              Conflicts
+	ResourceExhaustion     *ResourceExhaustion
+	NetworkIssues          map[string]NetworkIssue
+	FlowControl            FlowControl
+	CertificationConflicts CertificationConflicts
+	LargeTransactions      LargeTransactions
+	OngoingDDLs            map[string]DDL
+}
+
 func (l LogCtx) MarshalJSON() ([]byte, error) {
-	return json.Marshal(struct {
-		FilePath               string
-		FileType               string
-		OwnIPs                 []string
-		OwnHashes              []string
-		OwnNames               []string
-		StateErrorLog          string
-		StateRecoveryLog       string
-		StatePostProcessingLog string
-		StateBackupLog         string
-		Version                string
-		SST                    SST
-		MyIdx                  string
-		MemberCount            int
-		Desynced               bool
-		HashToIP               map[string]string
-		HashToNodeName         map[string]string
-		IPToHostname           map[string]string
-		IPToMethod             map[string]string
-		IPToNodeName           map[string]string
-		MinVerbosity           Verbosity
-		Conflicts              Conflicts
-	}{
+	return json.Marshal(logCtxJSON{
 		FilePath:               l.FilePath,
 		FileType:               l.FileType,
 		OwnIPs:                 l.OwnIPs,
 		OwnHashes:              l.OwnHashes,
+		OwnNames:               l.OwnNames,
+		Hostname:               l.Hostname,
 		StateErrorLog:          l.stateErrorLog,
 		StateRecoveryLog:       l.stateRecoveryLog,
 		StatePostProcessingLog: l.statePostProcessingLog,
@@ -270,6 +328,7 @@ func (l LogCtx) MarshalJSON() ([]byte, error) {
 		SST:                    l.SST,
 		MyIdx:                  l.MyIdx,
 		MemberCount:            l.MemberCount,
+		Views:                  l.Views,
 		Desynced:               l.Desynced,
 		HashToIP:               l.HashToIP,
 		HashToNodeName:         l.HashToNodeName,
@@ -278,5 +337,121 @@ func (l LogCtx) MarshalJSON() ([]byte, error) {
 		IPToNodeName:           l.IPToNodeName,
 		MinVerbosity:           l.minVerbosity,
 		Conflicts:              l.Conflicts,
+		ResourceExhaustion:     l.ResourceExhaustion,
+		NetworkIssues:          l.NetworkIssues,
+		FlowControl:            l.FlowControl,
+		CertificationConflicts: l.CertificationConflicts,
+		LargeTransactions:      l.LargeTransactions,
+		OngoingDDLs:            l.OngoingDDLs,
 	})
 }
+
+// UnmarshalJSON loads a context dumped by MarshalJSON
+// maps are never nil, so that handlers can use the context as if it came from NewLogCtx
+func (l *LogCtx) UnmarshalJSON(b []byte) error {
+	dump := logCtxJSON{MinVerbosity: Debug}
+	if err := json.Unmarshal(b, &dump); err != nil {
+		return err
+	}
+	ctx := NewLogCtx()
+	ctx.FilePath = dump.FilePath
+	ctx.FileType = dump.FileType
+	ctx.OwnIPs = dump.OwnIPs
+	ctx.OwnHashes = dump.OwnHashes
+	ctx.OwnNames = dump.OwnNames
+	ctx.Hostname = dump.Hostname
+	ctx.stateErrorLog = dump.StateErrorLog
+	ctx.stateRecoveryLog = dump.StateRecoveryLog
+	ctx.statePostProcessingLog = dump.StatePostProcessingLog
+	ctx.stateBackupLog = dump.StateBackupLog
+	ctx.Version = dump.Version
+	ctx.SST = dump.SST
+	ctx.MyIdx = dump.MyIdx
+	ctx.MemberCount = dump.MemberCount
+	ctx.Views = dump.Views
+	ctx.Desynced = dump.Desynced
+	ctx.minVerbosity = dump.MinVerbosity
+	ctx.Conflicts = dump.Conflicts
+	ctx.ResourceExhaustion = dump.ResourceExhaustion
+	ctx.FlowControl = dump.FlowControl
+	ctx.CertificationConflicts = dump.CertificationConflicts
+	ctx.LargeTransactions = dump.LargeTransactions
+	for key, ddl := range dump.OngoingDDLs {
+		ctx.OngoingDDLs[key] = ddl
+	}
+	for peer, issue := range dump.NetworkIssues {
+		ctx.NetworkIssues[peer] = issue
+	}
+	for _, m := range []struct {
+		from map[string]string
+		to   map[string]string
+	}{
+		{dump.HashToIP, ctx.HashToIP},
+		{dump.HashToNodeName, ctx.HashToNodeName},
+		{dump.IPToHostname, ctx.IPToHostname},
+		{dump.IPToMethod, ctx.IPToMethod},
+		{dump.IPToNodeName, ctx.IPToNodeName},
+	} {
+		for k, v := range m.from {
+			m.to[k] = v
+		}
+	}
+	*l = ctx
+	return nil
+}
+
+// CtxChange is a field of the context that was changed by a handler, as dumped in json
+type CtxChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// DiffCtx lists the fields that changed between 2 contexts, sorted by name
+func DiffCtx(before, after LogCtx) ([]CtxChange, error) {
+	var b, a map[string]interface{}
+	for _, c := range []struct {
+		ctx LogCtx
+		out *map[string]interface{}
+	}{{before, &b}, {after, &a}} {
+		raw, err := json.Marshal(c.ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw, c.out); err != nil {
+			return nil, err
+		}
+	}
+
+	fields := []string{}
+	for field := range a {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	changes := []CtxChange{}
+	for _, field := range fields {
+		if reflect.DeepEqual(b[field], a[field]) {
+			continue
+		}
+		before, after := b[field], a[field]
+		// only the keys that changed are kept, maps can be huge
+		bm, bok := before.(map[string]interface{})
+		am, aok := after.(map[string]interface{})
+		if bok && aok {
+			before, after = changedKeys(bm, am), changedKeys(am, bm)
+		}
+		changes = append(changes, CtxChange{Field: field, Before: before, After: after})
+	}
+	return changes, nil
+}
+
+func changedKeys(m, other map[string]interface{}) map[string]interface{} {
+	changed := map[string]interface{}{}
+	for k, v := range m {
+		if otherV, ok := other[k]; !ok || !reflect.DeepEqual(v, otherV) {
+			changed[k] = v
+		}
+	}
+	return changed
+}
@@ -0,0 +1,81 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestLogCtxJSON(t *testing.T) {
+	ctx := NewLogCtx()
+	ctx.FileType = "error.log"
+	ctx.SetState("SYNCED")
+	ctx.AddOwnName("node1")
+	ctx.AddOwnIP("172.17.0.2")
+	ctx.HashToNodeName["838ebd6d"] = "node2"
+
+	out, err := json.Marshal(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := LogCtx{}
+	if err := json.Unmarshal(out, &loaded); err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.State() != "SYNCED" {
+		t.Errorf("expected state SYNCED, got %s", loaded.State())
+	}
+	if !reflect.DeepEqual(loaded.OwnNames, []string{"node1"}) || !reflect.DeepEqual(loaded.OwnIPs, []string{"172.17.0.2"}) {
+		t.Errorf("unexpected own names or ips: %v %v", loaded.OwnNames, loaded.OwnIPs)
+	}
+	if loaded.HashToNodeName["838ebd6d"] != "node2" {
+		t.Errorf("expected hash to be translated, got %v", loaded.HashToNodeName)
+	}
+	// maps have to be usable by handlers
+	loaded.IPToMethod["172.17.0.3"] = "ssl"
+}
+
+func TestDiffCtx(t *testing.T) {
+	before := NewLogCtx()
+	before.HashToIP["838ebd6d"] = "172.17.0.2"
+	before.HashToIP["9509c194"] = "172.17.0.3"
+
+	after := NewLogCtx()
+	after.HashToIP["838ebd6d"] = "172.17.0.4"
+	after.HashToIP["9509c194"] = "172.17.0.3"
+	after.AddOwnName("node1")
+
+	changes, err := DiffCtx(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []CtxChange{
+		{Field: "HashToIP", Before: map[string]interface{}{"838ebd6d": "172.17.0.2"}, After: map[string]interface{}{"838ebd6d": "172.17.0.4"}},
+		{Field: "OwnNames", Before: nil, After: []interface{}{"node1"}},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("expected %v, got %v", expected, changes)
+	}
+}
+
+func TestSetHostname(t *testing.T) {
+	ctx := NewLogCtx()
+	ctx.AddOwnIP("172.17.0.2")
+	ctx.SetHostname("db1")
+
+	if len(ctx.OwnNames) != 0 {
+		t.Errorf("the hostname should not be used as a node name, got %v", ctx.OwnNames)
+	}
+	if ctx.IPToHostname["172.17.0.2"] != "db1" {
+		t.Errorf("expected own ip to be resolved to the hostname, got %v", ctx.IPToHostname)
+	}
+	if id := Identifier(ctx); id != "db1" {
+		t.Errorf("expected the hostname to be displayed until galera gives a name, got %s", id)
+	}
+
+	ctx.AddOwnName("node1")
+	if id := Identifier(ctx); id != "node1" {
+		t.Errorf("expected the node name to be preferred, got %s", id)
+	}
+}
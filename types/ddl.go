@@ -0,0 +1,94 @@
+package types
+
+import (
+	"strings"
+	"time"
+)
+
+type DDLMethod string
+
+const (
+	TOI DDLMethod = "TOI" // total order isolation: the whole cluster waits for it
+	RSU DDLMethod = "RSU" // rolling schema upgrade: the node is desynced while it runs
+	NBO DDLMethod = "NBO" // non-blocking operation: the cluster only waits for it to begin and end
+)
+
+// ddlQueryMaxLength is where queries are truncated, they are only there to recognize the DDL
+const ddlQueryMaxLength = 60
+
+// DDL is a schema change, as seen from a node
+type DDL struct {
+	Method DDLMethod `json:"method"`
+	Query  string    `json:"query"`
+	ConnID string    `json:"connID,omitempty"`
+	Seqno  string    `json:"seqno,omitempty"`
+	Start  *Date     `json:"start,omitempty"`
+}
+
+// NewDDL truncates the query, and gets rid of its newlines
+func NewDDL(method DDLMethod, query, connID, seqno string, start *Date) DDL {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > ddlQueryMaxLength {
+		query = query[:ddlQueryMaxLength] + "..."
+	}
+	return DDL{Method: method, Query: query, ConnID: connID, Seqno: seqno, Start: start}
+}
+
+// key pairs the start and the end of a DDL, they are logged by the same connection
+func (ddl DDL) key() string {
+	if ddl.ConnID != "" {
+		return string(ddl.Method) + " " + ddl.ConnID
+	}
+	return string(ddl.Method) + " " + ddl.Query
+}
+
+// Impact is what the cluster went through during the DDL
+func (ddl DDL) Impact() string {
+	switch ddl.Method {
+	case TOI:
+		return "blocked cluster"
+	case RSU:
+		return "node desynced"
+	}
+	return "non-blocking"
+}
+
+// StartDDL and EndDDL copy OngoingDDLs: it is shared with the contexts of previous log lines, they should keep their own DDLs
+func (ctx *LogCtx) StartDDL(ddl DDL) {
+	ongoing := ctx.copyOngoingDDLs()
+	ongoing[ddl.key()] = ddl
+	ctx.OngoingDDLs = ongoing
+}
+
+// EndDDL gives the DDL started by the same connection, with how long it ran
+func (ctx *LogCtx) EndDDL(ddl DDL) (DDL, time.Duration, bool) {
+	started, ok := ctx.OngoingDDLs[ddl.key()]
+	if !ok {
+		return ddl, 0, false
+	}
+	ongoing := ctx.copyOngoingDDLs()
+	delete(ongoing, ddl.key())
+	ctx.OngoingDDLs = ongoing
+	if started.Start == nil || ddl.Start == nil {
+		return started, 0, false
+	}
+	return started, ddl.Start.Time.Sub(started.Start.Time), true
+}
+
+func (ctx *LogCtx) copyOngoingDDLs() map[string]DDL {
+	ongoing := make(map[string]DDL, len(ctx.OngoingDDLs)+1)
+	for key, ddl := range ctx.OngoingDDLs {
+		ongoing[key] = ddl
+	}
+	return ongoing
+}
+
+// OngoingDDL is any DDL of this method that did not end yet
+func (ctx LogCtx) OngoingDDL(method DDLMethod) (DDL, bool) {
+	for _, ddl := range ctx.OngoingDDLs {
+		if ddl.Method == method {
+			return ddl, true
+		}
+	}
+	return DDL{}, false
+}
@@ -0,0 +1,98 @@
+package types
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewDDL(t *testing.T) {
+	ddl := NewDDL(TOI, "ALTER TABLE db.t\n  ADD COLUMN c INT", "12", "-1", nil)
+	if ddl.Query != "ALTER TABLE db.t ADD COLUMN c INT" {
+		t.Errorf("whitespaces were not collapsed: %q", ddl.Query)
+	}
+
+	ddl = NewDDL(TOI, "ALTER TABLE db.t "+strings.Repeat("ADD COLUMN c INT, ", 10), "12", "-1", nil)
+	if len(ddl.Query) != ddlQueryMaxLength+len("...") || !strings.HasSuffix(ddl.Query, "...") {
+		t.Errorf("query was not truncated: %q", ddl.Query)
+	}
+}
+
+func TestEndDDL(t *testing.T) {
+	start := NewDate(time.Date(2001, 1, 1, 1, 1, 1, 0, time.UTC), "")
+	end := NewDate(time.Date(2001, 1, 1, 1, 1, 43, 0, time.UTC), "")
+
+	tests := []struct {
+		name             string
+		started          []DDL
+		ended            DDL
+		expectedQuery    string
+		expectedDuration time.Duration
+		expectedOk       bool
+		expectedOngoing  int
+	}{
+		{
+			name:             "same connection",
+			started:          []DDL{NewDDL(TOI, "ALTER TABLE db.t1", "12", "-1", start)},
+			ended:            NewDDL(TOI, "ALTER TABLE db.t1", "12", "1234", end),
+			expectedQuery:    "ALTER TABLE db.t1",
+			expectedDuration: 42 * time.Second,
+			expectedOk:       true,
+		},
+		{
+			name:            "other connection",
+			started:         []DDL{NewDDL(TOI, "ALTER TABLE db.t1", "12", "-1", start)},
+			ended:           NewDDL(TOI, "ALTER TABLE db.t2", "13", "1234", end),
+			expectedQuery:   "ALTER TABLE db.t2",
+			expectedOngoing: 1,
+		},
+		{
+			name:            "other method on the same connection",
+			started:         []DDL{NewDDL(RSU, "ALTER TABLE db.t1", "12", "-1", start)},
+			ended:           NewDDL(TOI, "ALTER TABLE db.t1", "12", "1234", end),
+			expectedQuery:   "ALTER TABLE db.t1",
+			expectedOngoing: 1,
+		},
+		{
+			name:             "no connection id, paired by query",
+			started:          []DDL{NewDDL(NBO, "ALTER TABLE db.t1", "", "-1", start), NewDDL(NBO, "ALTER TABLE db.t2", "", "-1", start)},
+			ended:            NewDDL(NBO, "ALTER TABLE db.t2", "", "1240", end),
+			expectedQuery:    "ALTER TABLE db.t2",
+			expectedDuration: 42 * time.Second,
+			expectedOk:       true,
+			expectedOngoing:  1,
+		},
+	}
+
+	for _, test := range tests {
+		ctx := NewLogCtx()
+		for _, ddl := range test.started {
+			ctx.StartDDL(ddl)
+		}
+		ddl, duration, ok := ctx.EndDDL(test.ended)
+		if ddl.Query != test.expectedQuery || duration != test.expectedDuration || ok != test.expectedOk {
+			t.Errorf("%s: expected %s %s %v, got %s %s %v", test.name, test.expectedQuery, test.expectedDuration, test.expectedOk, ddl.Query, duration, ok)
+		}
+		if len(ctx.OngoingDDLs) != test.expectedOngoing {
+			t.Errorf("%s: expected %d ongoing DDLs, got %d", test.name, test.expectedOngoing, len(ctx.OngoingDDLs))
+		}
+	}
+}
+
+func TestDDLCopiesOngoing(t *testing.T) {
+	ctx := NewLogCtx()
+	initial := ctx
+	ctx.StartDDL(NewDDL(RSU, "ALTER TABLE db.t ADD INDEX (c)", "12", "-1", nil))
+	started := ctx
+
+	ctx.EndDDL(NewDDL(RSU, "ALTER TABLE db.t ADD INDEX (c)", "12", "1234", nil))
+	if len(initial.OngoingDDLs) != 0 {
+		t.Errorf("the context before the DDL started was modified: %v", initial.OngoingDDLs)
+	}
+	if _, ok := started.OngoingDDL(RSU); !ok {
+		t.Errorf("the context during the DDL lost it when it ended")
+	}
+	if _, ok := ctx.OngoingDDL(RSU); ok {
+		t.Errorf("expected the DDL to be ended")
+	}
+}
@@ -0,0 +1,54 @@
+package types
+
+import "sort"
+
+// DiscoveredTemplate is a message no regex knows about, with its variable parts replaced by placeholders
+type DiscoveredTemplate struct {
+	Template string         `json:"template"`
+	Example  string         `json:"example"` // the first line found
+	Count    int            `json:"count"`
+	PerNode  map[string]int `json:"perNode"`
+	First    *Date          `json:"first,omitempty"`
+	Last     *Date          `json:"last,omitempty"`
+}
+
+// DiscoveredTemplates are keyed by template
+type DiscoveredTemplates map[string]*DiscoveredTemplate
+
+// Add counts a line, its date can be nil
+func (d DiscoveredTemplates) Add(template, line, node string, date *Date) {
+	t, ok := d[template]
+	if !ok {
+		t = &DiscoveredTemplate{Template: template, Example: line, PerNode: map[string]int{}}
+		d[template] = t
+	}
+	t.Count++
+	t.PerNode[node]++
+	if date == nil {
+		return
+	}
+	if t.First == nil || date.Time.Before(t.First.Time) {
+		t.First = date
+	}
+	if t.Last == nil || date.Time.After(t.Last.Time) {
+		t.Last = date
+	}
+}
+
+// Top gives the n most frequent templates. Every templates are given when n <= 0
+func (d DiscoveredTemplates) Top(n int) []DiscoveredTemplate {
+	templates := []DiscoveredTemplate{}
+	for _, t := range d {
+		templates = append(templates, *t)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].Count != templates[j].Count {
+			return templates[i].Count > templates[j].Count
+		}
+		return templates[i].Template < templates[j].Template
+	})
+	if n > 0 && len(templates) > n {
+		templates = templates[:n]
+	}
+	return templates
+}
@@ -0,0 +1,93 @@
+package types
+
+import (
+	"strings"
+
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+// EVSDump is the evs::proto state galera prints when it is "going to give up"
+// It tells how every known node was perceived, which is what we need to understand partitions
+type EVSDump struct {
+	Self   string    `json:"self"` // short uuid of the node that printed the dump
+	State  string    `json:"state"`
+	ViewID string    `json:"viewID"`
+	Known  []EVSNode `json:"known"`
+}
+
+// EVSNode is a known node, as seen by the node that printed the dump
+type EVSNode struct {
+	UUID        string    `json:"uuid"`
+	Address     string    `json:"address,omitempty"`
+	Operational bool      `json:"operational"`
+	Suspected   bool      `json:"suspected"`
+	Installed   bool      `json:"installed"`
+	FifoSeq     string    `json:"fifoSeq"`
+	Sees        []EVSPeer `json:"sees,omitempty"` // from its last join message, when we received one
+}
+
+// EVSPeer is how a node sees one of its peers
+type EVSPeer struct {
+	UUID        string `json:"uuid"`
+	Operational bool   `json:"operational"`
+	Suspected   bool   `json:"suspected"`
+	ViewID      string `json:"viewID"`
+	SafeSeq     string `json:"safeSeq"`
+}
+
+func (p EVSPeer) problem() string {
+	switch {
+	case p.Suspected && !p.Operational:
+		return "suspected and inactive"
+	case p.Suspected:
+		return "suspected"
+	case !p.Operational:
+		return "inactive"
+	}
+	return ""
+}
+
+// Matrix renders who sees who as suspected or inactive
+// eg: node3 sees node1 as suspected, node2 inactive; node2 sees everyone operational
+func (d EVSDump) Matrix(ctx LogCtx) string {
+	perceptions := []string{}
+
+	// the dumping node perception is in the "known" flags
+	local := []EVSPeer{}
+	for _, n := range d.Known {
+		if n.UUID != d.Self {
+			local = append(local, EVSPeer{UUID: n.UUID, Operational: n.Operational, Suspected: n.Suspected})
+		}
+	}
+	perceptions = append(perceptions, d.perception(ctx, d.Self, local))
+
+	for _, n := range d.Known {
+		if n.UUID == d.Self || len(n.Sees) == 0 {
+			continue
+		}
+		perceptions = append(perceptions, d.perception(ctx, n.UUID, n.Sees))
+	}
+	return strings.Join(perceptions, "; ")
+}
+
+func (d EVSDump) perception(ctx LogCtx, node string, peers []EVSPeer) string {
+	problems := []string{}
+	for _, p := range peers {
+		if p.UUID == node {
+			continue
+		}
+		problem := p.problem()
+		if problem == "" {
+			continue
+		}
+		if len(problems) == 0 {
+			problem = "as " + problem
+		}
+		problems = append(problems, DisplayHashSimplestForm(ctx, p.UUID)+" "+problem)
+	}
+	name := DisplayHashSimplestForm(ctx, node)
+	if len(problems) == 0 {
+		return name + " sees everyone operational"
+	}
+	return name + " sees " + utils.Paint(utils.YellowText, strings.Join(problems, ", "))
+}
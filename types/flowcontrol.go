@@ -0,0 +1,85 @@
+package types
+
+import (
+	"sort"
+	"time"
+)
+
+// FlowControl is what a node logged about flow control
+// Pauses are only known from the node that asked for them: it is the one the cluster waits for
+// Receiving FC_STOP/FC_CONT is never logged, galera only counts them in wsrep_flow_control_recv and wsrep_flow_control_paused
+// the only trace of a received flow control message is when it is ignored because the node is non-primary
+type FlowControl struct {
+	Interval    string             `json:"interval,omitempty"` // eg: "[100, 100]", the receive queue limits
+	PausedSince *Date              `json:"pausedSince,omitempty"`
+	Pauses      []FlowControlPause `json:"pauses,omitempty"`
+	Ignored     int                `json:"ignored,omitempty"` // flow control messages received while non-primary
+}
+
+// FlowControlPause is a period where the node paused the writes of the whole cluster
+type FlowControlPause struct {
+	Node  string `json:"node,omitempty"`
+	Start *Date  `json:"start"`
+	End   *Date  `json:"end,omitempty"` // nil when the log ended before writes were resumed
+}
+
+// Pause is when the node asks the cluster to stop, it can ask several times before resuming
+func (fc *FlowControl) Pause(date *Date) {
+	if fc.PausedSince == nil {
+		fc.PausedSince = date
+	}
+}
+
+// Resume ends the current pause, if its start was found
+func (fc *FlowControl) Resume(date *Date) (FlowControlPause, bool) {
+	if fc.PausedSince == nil {
+		return FlowControlPause{}, false
+	}
+	pause := FlowControlPause{Start: fc.PausedSince, End: date}
+	// the slice is shared with the contexts of previous log lines, they should not get the pause
+	fc.Pauses = append(fc.Pauses[:len(fc.Pauses):len(fc.Pauses)], pause)
+	fc.PausedSince = nil
+	return pause, true
+}
+
+func (p FlowControlPause) Duration() (time.Duration, bool) {
+	if p.Start == nil || p.End == nil {
+		return 0, false
+	}
+	return p.End.Time.Sub(p.Start.Time), true
+}
+
+// Inherit keeps the pauses and ignored messages of both contexts, the previous ones first
+func (fc *FlowControl) Inherit(previous FlowControl) {
+	// copied: appending to the previous slice could write in the backing array of the previous file contexts
+	fc.Pauses = append(append([]FlowControlPause(nil), previous.Pauses...), fc.Pauses...)
+	fc.Ignored += previous.Ignored
+	if fc.Interval == "" {
+		fc.Interval = previous.Interval
+	}
+}
+
+// FlowControlPauses lists the pauses of every nodes, sorted by date
+// pauses still ongoing at the end of the logs are included
+func FlowControlPauses(ctxs map[string]LogCtx) []FlowControlPause {
+	pauses := []FlowControlPause{}
+	for node, ctx := range ctxs {
+		for _, pause := range ctx.FlowControl.Pauses {
+			pause.Node = node
+			pauses = append(pauses, pause)
+		}
+		if ctx.FlowControl.PausedSince != nil {
+			pauses = append(pauses, FlowControlPause{Node: node, Start: ctx.FlowControl.PausedSince})
+		}
+	}
+	sort.SliceStable(pauses, func(i, j int) bool {
+		if pauses[i].Start == nil || pauses[j].Start == nil {
+			return pauses[j].Start == nil && pauses[i].Start != nil
+		}
+		if !pauses[i].Start.Time.Equal(pauses[j].Start.Time) {
+			return pauses[i].Start.Time.Before(pauses[j].Start.Time)
+		}
+		return pauses[i].Node < pauses[j].Node
+	})
+	return pauses
+}
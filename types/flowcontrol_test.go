@@ -0,0 +1,84 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFlowControlPauses(t *testing.T) {
+	date := func(sec int) *Date {
+		return NewDate(time.Date(2023, 1, 1, 1, 1, sec, 0, time.UTC), "2006-01-02T15:04:05.000000Z")
+	}
+	ctxs := map[string]LogCtx{
+		"node1": {FlowControl: FlowControl{Pauses: []FlowControlPause{{Start: date(10), End: date(12)}}, PausedSince: date(30)}},
+		"node2": {FlowControl: FlowControl{Pauses: []FlowControlPause{{Start: date(1), End: date(5)}}}},
+		"node3": {},
+	}
+
+	pauses := FlowControlPauses(ctxs)
+	if len(pauses) != 3 {
+		t.Fatalf("expected 3 pauses, got %d", len(pauses))
+	}
+	expected := []struct {
+		node     string
+		duration time.Duration
+		ongoing  bool
+	}{
+		{node: "node2", duration: 4 * time.Second},
+		{node: "node1", duration: 2 * time.Second},
+		{node: "node1", ongoing: true},
+	}
+	for i, e := range expected {
+		d, ok := pauses[i].Duration()
+		if pauses[i].Node != e.node || ok == e.ongoing || d != e.duration {
+			t.Errorf("pause %d: expected %s, %s, ongoing: %v, got %s, %s, ongoing: %v", i, e.node, e.duration, e.ongoing, pauses[i].Node, d, !ok)
+		}
+	}
+}
+
+func TestFlowControlInherit(t *testing.T) {
+	date := func(sec int) *Date {
+		return NewDate(time.Date(2023, 1, 1, 1, 1, sec, 0, time.UTC), "2006-01-02T15:04:05.000000Z")
+	}
+	previous := FlowControl{Interval: "[100, 100]", Pauses: []FlowControlPause{{Start: date(1), End: date(2)}}, Ignored: 2}
+	current := FlowControl{Pauses: []FlowControlPause{{Start: date(10), End: date(12)}}, Ignored: 1}
+
+	current.Inherit(previous)
+	if len(current.Pauses) != 2 || current.Pauses[0].Start != previous.Pauses[0].Start {
+		t.Errorf("expected previous pauses first, got %v", current.Pauses)
+	}
+	if current.Ignored != 3 {
+		t.Errorf("expected ignored messages to be summed, got %d", current.Ignored)
+	}
+	if current.Interval != "[100, 100]" {
+		t.Errorf("expected the interval to be inherited, got %s", current.Interval)
+	}
+}
+
+func TestFlowControlInheritCopiesPauses(t *testing.T) {
+	date := func(sec int) *Date {
+		return NewDate(time.Date(2023, 1, 1, 1, 1, sec, 0, time.UTC), "2006-01-02T15:04:05.000000Z")
+	}
+	// spare capacity: appending to it would not allocate
+	previous := FlowControl{Pauses: make([]FlowControlPause, 1, 4)}
+	previous.Pauses[0] = FlowControlPause{Start: date(1), End: date(2)}
+
+	current := FlowControl{Pauses: []FlowControlPause{{Start: date(10), End: date(12)}}}
+	current.Inherit(previous)
+	other := FlowControl{Pauses: []FlowControlPause{{Start: date(20), End: date(22)}}}
+	other.Inherit(previous)
+
+	if !current.Pauses[1].Start.Time.Equal(date(10).Time) {
+		t.Errorf("merged pauses were overwritten by another merge: %v", current.Pauses)
+	}
+
+	snapshot := current
+	current.Pause(date(30))
+	current.Resume(date(31))
+	if len(snapshot.Pauses) != 2 || len(current.Pauses) != 3 {
+		t.Errorf("expected the pause to only be added to the current context, got %d and %d", len(snapshot.Pauses), len(current.Pauses))
+	}
+	if !other.Pauses[1].Start.Time.Equal(date(20).Time) {
+		t.Errorf("merged pauses were overwritten: %v", other.Pauses)
+	}
+}
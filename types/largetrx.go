@@ -0,0 +1,61 @@
+package types
+
+type LargeTransactionKind string
+
+const (
+	WriteSetTooLarge       LargeTransactionKind = "write set too large"       // over wsrep_max_ws_size, the trx is rolled back
+	SRFragmentApplyFailure LargeTransactionKind = "SR fragment apply failure" // a streaming replication fragment could not be applied
+)
+
+// LargeTransaction is what was logged about a transaction too big to be replicated at once
+type LargeTransaction struct {
+	Kind   LargeTransactionKind `json:"kind"`
+	Date   *Date                `json:"date,omitempty"`
+	ConnID string               `json:"connID,omitempty"`
+	TrxID  string               `json:"trxID,omitempty"`
+	Size   int64                `json:"size,omitempty"` // bytes, 0 when unknown
+}
+
+// LargeTransactions keeps counts, the last ones are enough to find which job is sending them
+type LargeTransactions struct {
+	Counts  map[LargeTransactionKind]int `json:"counts,omitempty"`
+	Largest int64                        `json:"largest,omitempty"` // bytes
+	Last    *LargeTransaction            `json:"last,omitempty"`
+}
+
+// Add records the transaction
+// Counts is copied: it is shared with the contexts of previous log lines, they should keep their counts
+func (lt *LargeTransactions) Add(trx LargeTransaction) {
+	counts := lt.copyCounts()
+	counts[trx.Kind]++
+	lt.Counts = counts
+	if trx.Size > lt.Largest {
+		lt.Largest = trx.Size
+	}
+	lt.Last = &trx
+}
+
+// Inherit sums the counts of both contexts, the last transaction is kept if there is no newer one
+func (lt *LargeTransactions) Inherit(previous LargeTransactions) {
+	if len(previous.Counts) > 0 {
+		counts := lt.copyCounts()
+		for kind, count := range previous.Counts {
+			counts[kind] += count
+		}
+		lt.Counts = counts
+	}
+	if previous.Largest > lt.Largest {
+		lt.Largest = previous.Largest
+	}
+	if lt.Last == nil {
+		lt.Last = previous.Last
+	}
+}
+
+func (lt *LargeTransactions) copyCounts() map[LargeTransactionKind]int {
+	counts := make(map[LargeTransactionKind]int, len(lt.Counts)+1)
+	for kind, count := range lt.Counts {
+		counts[kind] = count
+	}
+	return counts
+}
@@ -0,0 +1,51 @@
+package types
+
+import "testing"
+
+func TestLargeTransactionsInherit(t *testing.T) {
+	previous := LargeTransactions{}
+	previous.Add(LargeTransaction{Kind: WriteSetTooLarge, ConnID: "12", Size: 3000})
+	previous.Add(LargeTransaction{Kind: SRFragmentApplyFailure, TrxID: "2696"})
+
+	current := LargeTransactions{}
+	current.Add(LargeTransaction{Kind: WriteSetTooLarge, ConnID: "48", Size: 2000})
+
+	current.Inherit(previous)
+	if current.Counts[WriteSetTooLarge] != 2 || current.Counts[SRFragmentApplyFailure] != 1 {
+		t.Errorf("counts were not summed: %v", current.Counts)
+	}
+	if current.Largest != 3000 {
+		t.Errorf("expected the largest from the previous context, got %d", current.Largest)
+	}
+	if current.Last == nil || current.Last.ConnID != "48" {
+		t.Errorf("expected the newest trx to be kept as the last one, got %v", current.Last)
+	}
+
+	empty := LargeTransactions{}
+	empty.Inherit(previous)
+	if empty.Last == nil || empty.Last.TrxID != "2696" {
+		t.Errorf("expected the last trx to be inherited, got %v", empty.Last)
+	}
+}
+
+func TestLargeTransactionsCopiesCounts(t *testing.T) {
+	ctx := NewLogCtx()
+	ctx.LargeTransactions.Add(LargeTransaction{Kind: WriteSetTooLarge, ConnID: "12"})
+	snapshot := ctx
+
+	ctx.LargeTransactions.Add(LargeTransaction{Kind: WriteSetTooLarge, ConnID: "48"})
+	if count := snapshot.LargeTransactions.Counts[WriteSetTooLarge]; count != 1 {
+		t.Errorf("previous context was modified by Add, expected 1 trx, got %d", count)
+	}
+
+	previous := NewLogCtx()
+	previous.LargeTransactions.Add(LargeTransaction{Kind: SRFragmentApplyFailure, TrxID: "2696"})
+	snapshot = ctx
+	ctx.Inherit(previous)
+	if count := snapshot.LargeTransactions.Counts[SRFragmentApplyFailure]; count != 0 {
+		t.Errorf("previous context was modified by Inherit, expected no SR failure, got %d", count)
+	}
+	if count := ctx.LargeTransactions.Counts[SRFragmentApplyFailure]; count != 1 {
+		t.Errorf("expected the SR failure to be inherited, got %d", count)
+	}
+}
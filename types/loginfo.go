@@ -79,12 +79,33 @@ type Date struct {
 	Layout      string
 }
 
+// DisplayLocation and DisplayLayout are used to display every dates the same way
+// When they are not set, dates are displayed as they were found in logs
+var (
+	DisplayLocation *time.Location
+	DisplayLayout   string
+)
+
+// DefaultDisplayLayout is used when only DisplayLocation is set: the original layout could lack the timezone
+const DefaultDisplayLayout = "2006-01-02T15:04:05.000000Z07:00"
+
 func NewDate(t time.Time, layout string) *Date {
 	return &Date{
 		Time:        t,
 		Layout:      layout,
-		DisplayTime: t.Format(layout),
+		DisplayTime: displayTime(t, layout),
+	}
+}
+
+func displayTime(t time.Time, layout string) string {
+	if DisplayLocation != nil {
+		t = t.In(DisplayLocation)
+		layout = DefaultDisplayLayout
+	}
+	if DisplayLayout != "" {
+		layout = DisplayLayout
 	}
+	return t.Format(layout)
 }
 
 // LogDisplayer is the handler to generate messages thanks to a context
@@ -2,6 +2,7 @@ package types
 
 import (
 	"testing"
+	"time"
 )
 
 func TestIsDuplicatedEvent(t *testing.T) {
@@ -51,3 +52,52 @@ func TestIsDuplicatedEvent(t *testing.T) {
 	}
 
 }
+
+func TestNewDateDisplay(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no timezone database")
+	}
+	defer func() {
+		DisplayLocation = nil
+		DisplayLayout = ""
+	}()
+
+	date := time.Date(2023, time.January, 5, 3, 24, 26, 0, time.UTC)
+	tests := []struct {
+		name     string
+		location *time.Location
+		layout   string
+		expected string
+	}{
+		{
+			name:     "as written",
+			expected: "2023-01-05T03:24:26.000000Z",
+		},
+		{
+			name:     "other timezone",
+			location: newYork,
+			expected: "2023-01-04T22:24:26.000000-05:00",
+		},
+		{
+			name:     "other layout",
+			layout:   "2006-01-02 15:04:05",
+			expected: "2023-01-05 03:24:26",
+		},
+		{
+			name:     "both",
+			location: newYork,
+			layout:   "2006-01-02 15:04:05 MST",
+			expected: "2023-01-04 22:24:26 EST",
+		},
+	}
+
+	for _, test := range tests {
+		DisplayLocation = test.location
+		DisplayLayout = test.layout
+		out := NewDate(date, "2006-01-02T15:04:05.000000Z")
+		if out.DisplayTime != test.expected {
+			t.Errorf("testname: %s, expected: %s, got: %s", test.name, test.expected, out.DisplayTime)
+		}
+	}
+}
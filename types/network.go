@@ -0,0 +1,59 @@
+package types
+
+// NetworkIssueKind tells if a network issue will fix itself or not
+type NetworkIssueKind string
+
+const (
+	NetworkMisconfiguration NetworkIssueKind = "misconfiguration" // wrong group name, TLS: retrying will not help
+	NetworkTransient        NetworkIssueKind = "transient"        // timeouts, unreachable peers
+)
+
+// NetworkIssue is the last network problem of a kind a node had with a peer
+type NetworkIssue struct {
+	Peer   string           `json:"peer,omitempty"` // ip, or hash when the ip is unknown. Empty when the log does not tell
+	Kind   NetworkIssueKind `json:"kind"`
+	Reason string           `json:"reason"`
+	Count  int              `json:"count"`
+	Last   *Date            `json:"last,omitempty"`
+}
+
+// NetworkIssueKey is how issues are stored in LogCtx.NetworkIssues: misconfigurations and transient issues with a peer are kept apart
+func NetworkIssueKey(kind NetworkIssueKind, peer string) string {
+	return string(kind) + "|" + peer
+}
+
+// AddNetworkIssue records an issue with a peer. The peer can be empty when the log does not tell
+// The map is copied: it is shared with the contexts of previous log lines, they should keep their counts
+func (ctx *LogCtx) AddNetworkIssue(peer string, kind NetworkIssueKind, reason string, date *Date) {
+	issues := make(map[string]NetworkIssue, len(ctx.NetworkIssues)+1)
+	for key, issue := range ctx.NetworkIssues {
+		issues[key] = issue
+	}
+	key := NetworkIssueKey(kind, peer)
+	issue := issues[key]
+	issue.Peer = peer
+	issue.Count++
+	issue.Kind = kind
+	issue.Reason = reason
+	issue.Last = date
+	issues[key] = issue
+	ctx.NetworkIssues = issues
+}
+
+// inheritNetworkIssues sums the counts of both contexts, the latest reason is kept
+func (base *LogCtx) inheritNetworkIssues(previous map[string]NetworkIssue) {
+	if len(previous) == 0 {
+		return
+	}
+	issues := make(map[string]NetworkIssue, len(base.NetworkIssues)+len(previous))
+	for key, issue := range previous {
+		issues[key] = issue
+	}
+	for key, issue := range base.NetworkIssues {
+		if old, ok := issues[key]; ok {
+			issue.Count += old.Count
+		}
+		issues[key] = issue
+	}
+	base.NetworkIssues = issues
+}
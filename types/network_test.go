@@ -0,0 +1,43 @@
+package types
+
+import "testing"
+
+func TestAddNetworkIssueCopiesMap(t *testing.T) {
+	ctx := NewLogCtx()
+	ctx.AddNetworkIssue("172.17.0.3", NetworkTransient, "could not find peer", nil)
+	snapshot := ctx
+
+	ctx.AddNetworkIssue("172.17.0.3", NetworkTransient, "could not find peer", nil)
+	ctx.AddNetworkIssue("172.17.0.3", NetworkMisconfiguration, "TLS handshake failed", nil)
+
+	if count := snapshot.NetworkIssues[NetworkIssueKey(NetworkTransient, "172.17.0.3")].Count; count != 1 {
+		t.Errorf("previous context was modified, expected 1 issue, got %d", count)
+	}
+	if count := ctx.NetworkIssues[NetworkIssueKey(NetworkTransient, "172.17.0.3")].Count; count != 2 {
+		t.Errorf("expected 2 transient issues, got %d", count)
+	}
+	if _, ok := ctx.NetworkIssues[NetworkIssueKey(NetworkMisconfiguration, "172.17.0.3")]; !ok {
+		t.Errorf("misconfigurations should be kept apart from transient issues")
+	}
+}
+
+func TestInheritNetworkIssues(t *testing.T) {
+	previous := NewLogCtx()
+	previous.AddNetworkIssue("172.17.0.3", NetworkTransient, "could not find peer", nil)
+	previous.AddNetworkIssue("", NetworkMisconfiguration, "wrong group", nil)
+
+	current := NewLogCtx()
+	current.AddNetworkIssue("172.17.0.3", NetworkTransient, "unreachable peers, relaying messages", nil)
+
+	current.Inherit(previous)
+	issue := current.NetworkIssues[NetworkIssueKey(NetworkTransient, "172.17.0.3")]
+	if issue.Count != 2 || issue.Reason != "unreachable peers, relaying messages" {
+		t.Errorf("expected counts to be summed and the latest reason kept, got %+v", issue)
+	}
+	if _, ok := current.NetworkIssues[NetworkIssueKey(NetworkMisconfiguration, "")]; !ok {
+		t.Errorf("issues from the previous context were not inherited")
+	}
+	if count := previous.NetworkIssues[NetworkIssueKey(NetworkTransient, "172.17.0.3")].Count; count != 1 {
+		t.Errorf("previous context was modified, got %d", count)
+	}
+}
@@ -3,6 +3,7 @@ package types
 import (
 	"encoding/json"
 	"regexp"
+	"sort"
 )
 
 // LogRegex is the work struct to work on lines that were sent by "grep"
@@ -17,19 +18,54 @@ type LogRegex struct {
 	// This ensure every hash/ip/nodenames are already known when crafting the message
 	Handler   func(map[string]string, LogCtx, string) (LogCtx, LogDisplayer)
 	Verbosity Verbosity // To be able to hide details from summaries
+
+	// MultiLine regexes are given the whole record: the dated line and every following lines without date
+	// Lines are separated by "\n". It is only supported by the native engine, grep only sends lines in isolation
+	MultiLine bool
+
+	// Supersedes lists line regexes that were approximations of this MultiLine one
+	// They are ignored whenever records are available, to avoid duplicated or untrustworthy events
+	Supersedes []string
+
+	// Order is the order regexes are applied on a line matched by several of them, lower first
+	// Regexes with the same order are applied by key
+	Order int
+
+	// Terminal regexes stop the evaluation of the line once their InternalRegex matched: the next ones are not applied
+	// It is for generic regexes that would be wrong on lines a more specific one already handled
+	Terminal bool
 }
 
 func (l *LogRegex) Handle(ctx LogCtx, line string) (LogCtx, LogDisplayer) {
 	if ctx.minVerbosity > l.Verbosity {
 		ctx.minVerbosity = l.Verbosity
 	}
+	mergedResults, ok := l.Submatches(line)
+	if !ok {
+		return ctx, nil
+	}
+	return l.Handler(mergedResults, ctx, line)
+}
+
+// Terminates tells if the next regexes should not be applied on the line
+func (l *LogRegex) Terminates(line string) bool {
+	if !l.Terminal {
+		return false
+	}
+	_, ok := l.Submatches(line)
+	return ok
+}
+
+// Submatches gives the named groups of InternalRegex
+// It returns false when InternalRegex does not match
+func (l *LogRegex) Submatches(line string) (map[string]string, bool) {
 	mergedResults := map[string]string{}
 	if l.InternalRegex == nil {
-		return l.Handler(mergedResults, ctx, line)
+		return mergedResults, true
 	}
 	slice := l.InternalRegex.FindStringSubmatch(line)
 	if len(slice) == 0 {
-		return ctx, nil
+		return nil, false
 	}
 	for _, subexpname := range l.InternalRegex.SubexpNames() {
 		if subexpname == "" { // 1st element is always empty for the complete regex
@@ -37,7 +73,7 @@ func (l *LogRegex) Handle(ctx LogCtx, line string) (LogCtx, LogDisplayer) {
 		}
 		mergedResults[subexpname] = slice[l.InternalRegex.SubexpIndex(subexpname)]
 	}
-	return l.Handler(mergedResults, ctx, line)
+	return mergedResults, true
 }
 
 func (l *LogRegex) MarshalJSON() ([]byte, error) {
@@ -46,9 +82,15 @@ func (l *LogRegex) MarshalJSON() ([]byte, error) {
 		InternalRegex string    `json:"internalRegex"`
 		Type          RegexType `json:"type"`
 		Verbosity     Verbosity `json:"verbosity"`
+		MultiLine     bool      `json:"multiLine,omitempty"`
+		Order         int       `json:"order,omitempty"`
+		Terminal      bool      `json:"terminal,omitempty"`
 	}{
 		Type:      l.Type,
 		Verbosity: l.Verbosity,
+		MultiLine: l.MultiLine,
+		Order:     l.Order,
+		Terminal:  l.Terminal,
 	}
 	if l.Regex != nil {
 		out.Regex = l.Regex.String()
@@ -70,6 +112,9 @@ var (
 	StatesRegexType      RegexType = "states"
 	PXCOperatorRegexType RegexType = "pxc-operator"
 	ApplicativeRegexType RegexType = "applicative"
+	ResourcesRegexType   RegexType = "resources"
+	NetworkRegexType     RegexType = "network"
+	DDLRegexType         RegexType = "ddl"
 )
 
 type RegexMap map[string]*LogRegex
@@ -89,3 +134,62 @@ func (r RegexMap) Compile() []string {
 	}
 	return arr
 }
+
+func (r RegexMap) Regexes() []*regexp.Regexp {
+
+	arr := []*regexp.Regexp{}
+	for _, regex := range r {
+		arr = append(arr, regex.Regex)
+	}
+	return arr
+}
+
+func (r RegexMap) SortedKeys() []string {
+
+	keys := make([]string, 0, len(r))
+	for key := range r {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// OrderedKeys gives the order regexes have to be applied on a line: by Order, then by key
+// Iterating over the map would give a different output on each run
+func (r RegexMap) OrderedKeys() []string {
+	keys := r.SortedKeys()
+	sort.SliceStable(keys, func(i, j int) bool {
+		return r[keys[i]].Order < r[keys[j]].Order
+	})
+	return keys
+}
+
+// SplitMultiLine separates regexes working on lines, and regexes working on whole records
+func (r RegexMap) SplitMultiLine() (RegexMap, RegexMap) {
+	lines, records := RegexMap{}, RegexMap{}
+	for key, regex := range r {
+		if regex.MultiLine {
+			records[key] = regex
+		} else {
+			lines[key] = regex
+		}
+	}
+	return lines, records
+}
+
+// WithoutSuperseded returns a copy without the line regexes replaced by MultiLine regexes of the map
+func (r RegexMap) WithoutSuperseded() RegexMap {
+	superseded := map[string]bool{}
+	for _, regex := range r {
+		for _, key := range regex.Supersedes {
+			superseded[key] = true
+		}
+	}
+	out := RegexMap{}
+	for key, regex := range r {
+		if !superseded[key] {
+			out[key] = regex
+		}
+	}
+	return out
+}
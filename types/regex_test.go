@@ -0,0 +1,55 @@
+package types
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestOrderedKeys(t *testing.T) {
+	regexes := RegexMap{
+		"RegexB":       &LogRegex{},
+		"RegexA":       &LogRegex{},
+		"RegexLast":    &LogRegex{Order: 1},
+		"RegexFirst":   &LogRegex{Order: -1},
+		"RegexAnother": &LogRegex{Order: -1},
+	}
+	expected := []string{"RegexAnother", "RegexFirst", "RegexA", "RegexB", "RegexLast"}
+	if keys := regexes.OrderedKeys(); !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %v, got %v", expected, keys)
+	}
+}
+
+func TestTerminates(t *testing.T) {
+	tests := []struct {
+		name     string
+		regex    LogRegex
+		line     string
+		expected bool
+	}{
+		{
+			name:     "not terminal",
+			regex:    LogRegex{InternalRegex: regexp.MustCompile("left the group")},
+			line:     "State transfer to -1.-1 (left the group) complete.",
+			expected: false,
+		},
+		{
+			name:     "terminal",
+			regex:    LogRegex{InternalRegex: regexp.MustCompile("left the group"), Terminal: true},
+			line:     "State transfer to -1.-1 (left the group) complete.",
+			expected: true,
+		},
+		{
+			name:     "terminal, internal regex did not match",
+			regex:    LogRegex{InternalRegex: regexp.MustCompile("left the group"), Terminal: true},
+			line:     "State transfer to 2.0 (node2) complete.",
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		if out := test.regex.Terminates(test.line); out != test.expected {
+			t.Errorf("testname: %s, expected: %t, got: %t", test.name, test.expected, out)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package types
+
+import (
+	"sort"
+	"strings"
+)
+
+// RegexStat tells how a regex behaved on a file
+// It helps finding regexes that are dead, noisy, or too loose
+type RegexStat struct {
+	Matches        int   `json:"matches"`
+	NilDisplayers  int   `json:"nilDisplayers"`  // the handler did not give anything to display
+	InternalMisses int   `json:"internalMisses"` // Regex matched, but not InternalRegex
+	First          *Date `json:"first,omitempty"`
+	Last           *Date `json:"last,omitempty"`
+}
+
+// Add counts a line matched by the regex
+func (s *RegexStat) Add(date *Date, displayer LogDisplayer, internalMiss bool) {
+	s.Matches++
+	if internalMiss {
+		s.InternalMisses++
+	} else if displayer == nil {
+		s.NilDisplayers++
+	}
+	if date == nil {
+		return
+	}
+	if s.First == nil || date.Time.Before(s.First.Time) {
+		s.First = date
+	}
+	if s.Last == nil || date.Time.After(s.Last.Time) {
+		s.Last = date
+	}
+}
+
+// Merge adds the stats of another file
+func (s *RegexStat) Merge(s2 RegexStat) {
+	s.Matches += s2.Matches
+	s.NilDisplayers += s2.NilDisplayers
+	s.InternalMisses += s2.InternalMisses
+	if s2.First != nil && (s.First == nil || s2.First.Time.Before(s.First.Time)) {
+		s.First = s2.First
+	}
+	if s2.Last != nil && (s.Last == nil || s2.Last.Time.After(s.Last.Time)) {
+		s.Last = s2.Last
+	}
+}
+
+// FileRegexStats are the stats of every regexes that matched in a file, keyed by regex
+type FileRegexStats struct {
+	Path    string                `json:"path"`
+	Node    string                `json:"node"`
+	Regexes map[string]*RegexStat `json:"regexes"`
+}
+
+// RegexStatsSummary is a regex, with its stats for the whole set of files
+type RegexStatsSummary struct {
+	Key       string               `json:"key"`
+	Total     RegexStat            `json:"total"`
+	PerFile   map[string]RegexStat `json:"perFile,omitempty"`
+	PerNode   map[string]RegexStat `json:"perNode,omitempty"`
+	NeverUsed bool                 `json:"neverUsed,omitempty"`
+}
+
+// SummarizeRegexStats gives a summary for every key, including regexes that never matched, sorted by key
+func SummarizeRegexStats(keys []string, files []FileRegexStats) []RegexStatsSummary {
+	summaries := []RegexStatsSummary{}
+	sorted := make([]string, len(keys))
+	copy(sorted, keys)
+	sort.Strings(sorted)
+
+	for _, key := range sorted {
+		summary := RegexStatsSummary{Key: key, PerFile: map[string]RegexStat{}, PerNode: map[string]RegexStat{}}
+		for _, file := range files {
+			stat, ok := file.Regexes[key]
+			if !ok {
+				continue
+			}
+			summary.Total.Merge(*stat)
+			summary.PerFile[file.Path] = *stat
+			nodeStat := summary.PerNode[file.Node]
+			nodeStat.Merge(*stat)
+			summary.PerNode[file.Node] = nodeStat
+		}
+		summary.NeverUsed = summary.Total.Matches == 0
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+// RegexOverlap is a set of regexes that all displayed something for the same lines
+// It usually means they are duplicates, or that one is missing Order or Terminal
+type RegexOverlap struct {
+	Keys   []string `json:"keys"`
+	Count  int      `json:"count"`
+	Sample string   `json:"sample"` // the first line found
+}
+
+// RegexOverlaps are keyed by their list of regexes
+type RegexOverlaps map[string]*RegexOverlap
+
+func (o RegexOverlaps) Add(keys []string, line string) {
+	id := strings.Join(keys, ",")
+	overlap, ok := o[id]
+	if !ok {
+		overlap = &RegexOverlap{Keys: keys, Sample: line}
+		o[id] = overlap
+	}
+	overlap.Count++
+}
+
+func (o RegexOverlaps) Merge(o2 RegexOverlaps) {
+	for id, overlap2 := range o2 {
+		overlap, ok := o[id]
+		if !ok {
+			copied := *overlap2
+			o[id] = &copied
+			continue
+		}
+		overlap.Count += overlap2.Count
+	}
+}
+
+// Sorted gives the most frequent overlaps first
+func (o RegexOverlaps) Sorted() []RegexOverlap {
+	overlaps := []RegexOverlap{}
+	for _, overlap := range o {
+		overlaps = append(overlaps, *overlap)
+	}
+	sort.Slice(overlaps, func(i, j int) bool {
+		if overlaps[i].Count != overlaps[j].Count {
+			return overlaps[i].Count > overlaps[j].Count
+		}
+		return strings.Join(overlaps[i].Keys, ",") < strings.Join(overlaps[j].Keys, ",")
+	})
+	return overlaps
+}
@@ -0,0 +1,77 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeRegexStats(t *testing.T) {
+	date := func(seconds int) *Date {
+		return NewDate(time.Date(2023, time.January, 1, 1, 1, seconds, 0, time.UTC), "2006-01-02T15:04:05Z")
+	}
+	displayer := func(LogCtx) string { return "" }
+
+	node1file1 := &RegexStat{}
+	node1file1.Add(date(3), displayer, false)
+	node1file1.Add(date(1), nil, false)
+	node1file2 := &RegexStat{}
+	node1file2.Add(date(10), nil, true)
+	node2 := &RegexStat{}
+	node2.Add(date(5), displayer, false)
+
+	files := []FileRegexStats{
+		{Path: "node1/error.log", Node: "node1", Regexes: map[string]*RegexStat{"RegexA": node1file1}},
+		{Path: "node1/error.log.1", Node: "node1", Regexes: map[string]*RegexStat{"RegexA": node1file2}},
+		{Path: "node2/error.log", Node: "node2", Regexes: map[string]*RegexStat{"RegexA": node2}},
+	}
+
+	summaries := SummarizeRegexStats([]string{"RegexB", "RegexA"}, files)
+	if len(summaries) != 2 || summaries[0].Key != "RegexA" || summaries[1].Key != "RegexB" {
+		t.Fatalf("expected summaries sorted by key, got %v", summaries)
+	}
+
+	a := summaries[0]
+	if a.NeverUsed {
+		t.Errorf("RegexA matched")
+	}
+	if a.Total.Matches != 4 || a.Total.NilDisplayers != 1 || a.Total.InternalMisses != 1 {
+		t.Errorf("unexpected total: %+v", a.Total)
+	}
+	if !a.Total.First.Time.Equal(date(1).Time) || !a.Total.Last.Time.Equal(date(10).Time) {
+		t.Errorf("unexpected first/last: %s %s", a.Total.First.DisplayTime, a.Total.Last.DisplayTime)
+	}
+	if a.PerNode["node1"].Matches != 3 || a.PerNode["node2"].Matches != 1 {
+		t.Errorf("unexpected per node: %+v", a.PerNode)
+	}
+	if len(a.PerFile) != 3 || a.PerFile["node1/error.log"].Matches != 2 {
+		t.Errorf("unexpected per file: %+v", a.PerFile)
+	}
+
+	if !summaries[1].NeverUsed || summaries[1].Total.Matches != 0 {
+		t.Errorf("RegexB never matched, got %+v", summaries[1])
+	}
+}
+
+func TestRegexOverlaps(t *testing.T) {
+	file1 := RegexOverlaps{}
+	file1.Add([]string{"RegexA", "RegexB"}, "line 1")
+	file1.Add([]string{"RegexA", "RegexB"}, "line 2")
+	file2 := RegexOverlaps{}
+	file2.Add([]string{"RegexA", "RegexB"}, "line 3")
+	file2.Add([]string{"RegexC", "RegexD"}, "line 4")
+
+	overlaps := RegexOverlaps{}
+	overlaps.Merge(file1)
+	overlaps.Merge(file2)
+	sorted := overlaps.Sorted()
+
+	if len(sorted) != 2 {
+		t.Fatalf("expected 2 overlaps, got %v", sorted)
+	}
+	if sorted[0].Count != 3 || sorted[0].Sample != "line 1" || sorted[1].Count != 1 {
+		t.Errorf("unexpected overlaps: %v", sorted)
+	}
+	if file1["RegexA,RegexB"].Count != 2 {
+		t.Errorf("merging should not modify the merged overlaps")
+	}
+}
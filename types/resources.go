@@ -0,0 +1,36 @@
+package types
+
+import "github.com/ylacancellera/galera-log-explainer/utils"
+
+// ResourceExhaustion is the last time a node ran out of something: disk, memory, open files
+type ResourceExhaustion struct {
+	Resource string `json:"resource"`
+	Date     *Date  `json:"date,omitempty"`
+}
+
+// PrecedingResourceExhaustion gives the resource exhaustion when it was the last event displayed at this verbosity
+func (lt LocalTimeline) PrecedingResourceExhaustion(verbosity Verbosity) (*ResourceExhaustion, bool) {
+	for i := len(lt) - 1; i >= 0; i-- {
+		li := lt[i]
+		// same as what is displayed by timelines
+		if li.displayer == nil || verbosity <= li.Verbosity {
+			continue
+		}
+		if li.RegexType != ResourcesRegexType || li.Ctx.ResourceExhaustion == nil {
+			return nil, false
+		}
+		return li.Ctx.ResourceExhaustion, true
+	}
+	return nil, false
+}
+
+// Highlight adds a warning at the end of the message
+func (li *LogInfo) Highlight(warning string) {
+	displayer := li.displayer
+	if displayer == nil {
+		return
+	}
+	li.displayer = func(ctx LogCtx) string {
+		return displayer(ctx) + utils.Paint(utils.BrightRedText, " ("+warning+")")
+	}
+}
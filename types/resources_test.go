@@ -0,0 +1,43 @@
+package types
+
+import "testing"
+
+func TestPrecedingResourceExhaustion(t *testing.T) {
+	displayer := SimpleDisplayer("something")
+	exhaustion := &ResourceExhaustion{Resource: "disk"}
+	starved := LogInfo{displayer: displayer, RegexType: ResourcesRegexType, Ctx: LogCtx{ResourceExhaustion: exhaustion}}
+
+	tests := []struct {
+		name     string
+		lt       LocalTimeline
+		expected bool
+	}{
+		{
+			name:     "empty",
+			lt:       LocalTimeline{},
+			expected: false,
+		},
+		{
+			name:     "directly preceding",
+			lt:       LocalTimeline{LogInfo{displayer: displayer, RegexType: EventsRegexType}, starved},
+			expected: true,
+		},
+		{
+			name:     "hidden events in between are skipped",
+			lt:       LocalTimeline{starved, LogInfo{RegexType: EventsRegexType}, LogInfo{displayer: displayer, RegexType: EventsRegexType, Verbosity: Detailed}},
+			expected: true,
+		},
+		{
+			name:     "another event in between",
+			lt:       LocalTimeline{starved, LogInfo{displayer: displayer, RegexType: EventsRegexType}},
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		_, ok := test.lt.PrecedingResourceExhaustion(Info + 1)
+		if ok != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, ok)
+		}
+	}
+}
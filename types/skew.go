@@ -0,0 +1,166 @@
+package types
+
+import (
+	"sort"
+	"time"
+)
+
+// ClockAnchor is an event every node logs at the same time, because it comes from a group message
+// eg: a view installation, an SST completion, an inconsistency vote
+type ClockAnchor struct {
+	Key  string
+	Time time.Time
+}
+
+// ClockSkew is how much a node clock is ahead of the reference node
+type ClockSkew struct {
+	Node      string        `json:"node" yaml:"node"`
+	Reference string        `json:"reference" yaml:"reference"`
+	Offset    time.Duration `json:"offset" yaml:"offset"`
+	Anchors   int           `json:"anchors" yaml:"anchors"` // number of shared events the offset was estimated from
+}
+
+type pairSkew struct {
+	offset  time.Duration
+	anchors int
+}
+
+// EstimateClockSkews compares when each node logged the same events
+// The reference is the node sharing events with the most nodes, every other offset is relative to it
+// Nodes that do not share any event with the others, even indirectly, are not returned
+func EstimateClockSkews(anchors map[string][]ClockAnchor) []ClockSkew {
+	nodes := []string{}
+	for node := range anchors {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	// pairs[a][b] is how much a is ahead of b
+	pairs := map[string]map[string]pairSkew{}
+	for _, node := range nodes {
+		pairs[node] = map[string]pairSkew{}
+	}
+	for i, a := range nodes {
+		for _, b := range nodes[i+1:] {
+			diffs := anchorDiffs(anchors[a], anchors[b])
+			if len(diffs) == 0 {
+				continue
+			}
+			offset := medianDuration(diffs)
+			pairs[a][b] = pairSkew{offset: offset, anchors: len(diffs)}
+			pairs[b][a] = pairSkew{offset: -offset, anchors: len(diffs)}
+		}
+	}
+
+	reference := ""
+	for _, node := range nodes {
+		if reference == "" || len(pairs[node]) > len(pairs[reference]) {
+			reference = node
+		}
+	}
+	if reference == "" || len(pairs[reference]) == 0 {
+		return nil
+	}
+
+	// nodes that do not share anything with the reference are estimated through another node
+	// the most trustworthy pairs, with the more anchors, are used first
+	skews := map[string]ClockSkew{reference: ClockSkew{Node: reference, Reference: reference}}
+	for {
+		var best ClockSkew
+		for _, known := range nodes {
+			knownSkew, ok := skews[known]
+			if !ok {
+				continue
+			}
+			for _, node := range nodes {
+				pair, ok := pairs[node][known]
+				if _, done := skews[node]; done || !ok || pair.anchors <= best.Anchors {
+					continue
+				}
+				best = ClockSkew{Node: node, Reference: reference, Offset: knownSkew.Offset + pair.offset, Anchors: pair.anchors}
+			}
+		}
+		if best.Node == "" {
+			break
+		}
+		skews[best.Node] = best
+	}
+
+	out := []ClockSkew{}
+	for _, node := range nodes {
+		if skew, ok := skews[node]; ok {
+			out = append(out, skew)
+		}
+	}
+	return out
+}
+
+// anchorDiffs pairs every anchors of a with the closest anchor with the same key in b
+// keys can be repeated (the same SST done twice), the closest one is the most likely to be the same event
+func anchorDiffs(a, b []ClockAnchor) []time.Duration {
+	byKey := map[string][]time.Time{}
+	for _, anchor := range b {
+		byKey[anchor.Key] = append(byKey[anchor.Key], anchor.Time)
+	}
+
+	diffs := []time.Duration{}
+	for _, anchor := range a {
+		candidates, ok := byKey[anchor.Key]
+		if !ok {
+			continue
+		}
+		closest := anchor.Time.Sub(candidates[0])
+		for _, t := range candidates[1:] {
+			if diff := anchor.Time.Sub(t); absDuration(diff) < absDuration(closest) {
+				closest = diff
+			}
+		}
+		diffs = append(diffs, closest)
+	}
+	return diffs
+}
+
+// the median ignores a few events logged late because a node was busy
+func medianDuration(durations []time.Duration) time.Duration {
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// CorrectClockSkews moves the dates of every nodes to the reference clock
+// Dates are copied, they can be shared with contexts
+func (timeline Timeline) CorrectClockSkews(skews []ClockSkew) {
+	for _, skew := range skews {
+		lt, ok := timeline[skew.Node]
+		if !ok || skew.Offset == 0 {
+			continue
+		}
+		for i := range lt {
+			lt[i].Ctx.ClockSkew = skew.Offset
+			if lt[i].Date == nil {
+				continue
+			}
+			lt[i].Date = NewDate(lt[i].Date.Time.Add(-skew.Offset), lt[i].Date.Layout)
+		}
+	}
+}
+
+// SignedDuration always shows the sign, to tell if it's ahead or behind
+func SignedDuration(d time.Duration) string {
+	if d > 0 {
+		return "+" + d.String()
+	}
+	return d.String()
+}
@@ -0,0 +1,83 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEstimateClockSkews(t *testing.T) {
+	base := time.Date(2023, time.January, 1, 1, 1, 1, 0, time.UTC)
+	at := func(seconds float64) time.Time {
+		return base.Add(time.Duration(seconds * float64(time.Second)))
+	}
+
+	tests := []struct {
+		name     string
+		input    map[string][]ClockAnchor
+		expected []ClockSkew
+	}{
+		{
+			name: "node2 is 4s ahead, one late event is ignored",
+			input: map[string][]ClockAnchor{
+				"node1": {{Key: "view a", Time: at(0)}, {Key: "view b", Time: at(60)}, {Key: "sst node1 node2", Time: at(100)}},
+				"node2": {{Key: "view a", Time: at(4)}, {Key: "view b", Time: at(64)}, {Key: "sst node1 node2", Time: at(130)}},
+			},
+			expected: []ClockSkew{
+				{Node: "node1", Reference: "node1"},
+				{Node: "node2", Reference: "node1", Offset: 4 * time.Second, Anchors: 3},
+			},
+		},
+		{
+			name: "node3 only shares events with node2, repeated keys use the closest event",
+			input: map[string][]ClockAnchor{
+				"node1": {{Key: "view a", Time: at(0)}},
+				"node2": {{Key: "view a", Time: at(-2)}, {Key: "sst node2 node3", Time: at(10)}, {Key: "sst node2 node3", Time: at(500)}},
+				"node3": {{Key: "sst node2 node3", Time: at(11)}, {Key: "sst node2 node3", Time: at(501)}},
+				"node4": {{Key: "view z", Time: at(0)}},
+			},
+			expected: []ClockSkew{
+				{Node: "node1", Reference: "node2", Offset: 2 * time.Second, Anchors: 1},
+				{Node: "node2", Reference: "node2"},
+				{Node: "node3", Reference: "node2", Offset: time.Second, Anchors: 2},
+			},
+		},
+		{
+			name: "nothing shared",
+			input: map[string][]ClockAnchor{
+				"node1": {{Key: "view a", Time: at(0)}},
+				"node2": {{Key: "view b", Time: at(0)}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		out := EstimateClockSkews(test.input)
+		if !reflect.DeepEqual(out, test.expected) {
+			t.Errorf("testname: %s, expected: \n%#v\n got: \n%#v", test.name, test.expected, out)
+		}
+	}
+}
+
+func TestCorrectClockSkews(t *testing.T) {
+	date := NewDate(time.Date(2023, time.January, 1, 1, 1, 5, 0, time.UTC), "2006-01-02T15:04:05.000000Z")
+	timeline := Timeline{
+		"node1": LocalTimeline{{Date: date}},
+		"node2": LocalTimeline{{Date: date}, {}},
+	}
+
+	timeline.CorrectClockSkews([]ClockSkew{{Node: "node1", Reference: "node1"}, {Node: "node2", Reference: "node1", Offset: 4 * time.Second}})
+
+	if timeline["node1"][0].Date.DisplayTime != "2023-01-01T01:01:05.000000Z" {
+		t.Errorf("reference should not be moved, got %s", timeline["node1"][0].Date.DisplayTime)
+	}
+	if timeline["node2"][0].Date.DisplayTime != "2023-01-01T01:01:01.000000Z" {
+		t.Errorf("expected node2 to be moved 4s back, got %s", timeline["node2"][0].Date.DisplayTime)
+	}
+	if timeline["node2"][1].Ctx.ClockSkew != 4*time.Second {
+		t.Errorf("expected the skew to be kept in contexts, got %s", timeline["node2"][1].Ctx.ClockSkew)
+	}
+	if date.DisplayTime != "2023-01-01T01:01:05.000000Z" {
+		t.Errorf("shared dates should not be modified")
+	}
+}
@@ -1,15 +1,132 @@
 package types
 
+import (
+	"strings"
+	"time"
+)
+
+// sstFailureWindow is how long after a failure the next errors are considered part of it
+// A failing script logs its errors in a row, then galera logs its own
+const sstFailureWindow = time.Minute
+
+// SST phases, as logged by the wsrep_sst_xtrabackup-v2, wsrep_sst_mariabackup and wsrep_sst_rsync scripts
+const (
+	SSTPhaseBackup    = "backup"
+	SSTPhaseStreaming = "streaming"
+	SSTPhaseReceiving = "receiving"
+	SSTPhaseKeyring   = "keyring transfer"
+	SSTPhasePrepare   = "prepare"
+	SSTPhaseMoveBack  = "move-back"
+)
+
 type SST struct {
 	Method           string
 	Type             string
 	ResyncingNode    string
 	ResyncedFromNode string
+	Phases           []SSTPhase
+	Failed           bool // the phases were ended by a failure of the script
+}
+
+type SSTPhase struct {
+	Name  string
+	Start *Date
+	End   *Date // nil while the phase is ongoing
+}
+
+func (p SSTPhase) Duration() (time.Duration, bool) {
+	if p.Start == nil || p.End == nil {
+		return 0, false
+	}
+	return p.End.Time.Sub(p.Start.Time), true
 }
 
+// Reset keeps the phases: the joiner can still be preparing when the donor is done
+// They are dropped when the next SST starts
 func (s *SST) Reset() {
 	s.Method = ""
 	s.Type = ""
 	s.ResyncedFromNode = ""
 	s.ResyncingNode = ""
 }
+
+// StartPhase ends the ongoing phase
+// When every phase already ended, the script ran until the end: it is a new SST
+func (s *SST) StartPhase(name string, date *Date) {
+	if len(s.Phases) > 0 {
+		last := s.Phases[len(s.Phases)-1]
+		switch {
+		case last.End != nil:
+			s.ClearPhases()
+		case last.Name == name: // scripts can log several lines for the same phase
+			return
+		default:
+			s.endLastPhase(date)
+		}
+	}
+	s.Phases = append(s.Phases[:len(s.Phases):len(s.Phases)], SSTPhase{Name: name, Start: date})
+}
+
+// EndPhases is when the script exited, successfully or not
+func (s *SST) EndPhases(date *Date) {
+	if s.Ongoing() {
+		s.endLastPhase(date)
+	}
+}
+
+// endLastPhase copies the phases: they are shared with the contexts of previous log lines, their phase did not end yet
+func (s *SST) endLastPhase(date *Date) {
+	phases := append([]SSTPhase(nil), s.Phases...)
+	phases[len(phases)-1].End = date
+	s.Phases = phases
+}
+
+// ClearPhases is for when a new SST starts, or when mysqld restarts: the previous phases are not relevant anymore
+func (s *SST) ClearPhases() {
+	s.Phases = nil
+	s.Failed = false
+}
+
+// Fail ends the ongoing phase because the script failed
+func (s *SST) Fail(date *Date) {
+	s.EndPhases(date)
+	s.Failed = true
+}
+
+// FailedPhase gives the phase where the script recently failed
+func (s SST) FailedPhase(date *Date) (string, bool) {
+	if !s.Failed || len(s.Phases) == 0 || date == nil {
+		return "", false
+	}
+	last := s.Phases[len(s.Phases)-1]
+	if last.End == nil || date.Time.Sub(last.End.Time) > sstFailureWindow {
+		return "", false
+	}
+	return last.Name, true
+}
+
+// Ongoing tells if the script is still running
+func (s SST) Ongoing() bool {
+	return len(s.Phases) > 0 && s.Phases[len(s.Phases)-1].End == nil
+}
+
+// CurrentPhase is the last phase reached, even if it ended
+func (s SST) CurrentPhase() string {
+	if len(s.Phases) == 0 {
+		return ""
+	}
+	return s.Phases[len(s.Phases)-1].Name
+}
+
+// PhasesSummary gives how long each phase took, eg: "receiving 2m1s, prepare 12s, move-back 3s"
+func (s SST) PhasesSummary() string {
+	summary := []string{}
+	for _, phase := range s.Phases {
+		if d, ok := phase.Duration(); ok {
+			summary = append(summary, phase.Name+" "+d.Round(time.Millisecond).String())
+		} else {
+			summary = append(summary, phase.Name)
+		}
+	}
+	return strings.Join(summary, ", ")
+}
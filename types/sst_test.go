@@ -0,0 +1,57 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSSTPhases(t *testing.T) {
+	at := func(sec int) *Date {
+		return NewDate(time.Date(2001, 1, 1, 1, 1, sec, 0, time.UTC), "")
+	}
+
+	sst := SST{}
+	sst.StartPhase(SSTPhaseReceiving, at(0))
+	sst.StartPhase(SSTPhaseReceiving, at(1))
+	sst.StartPhase(SSTPhasePrepare, at(10))
+	if sst.CurrentPhase() != SSTPhasePrepare {
+		t.Errorf("expected to be in prepare, got %s", sst.CurrentPhase())
+	}
+	if summary := sst.PhasesSummary(); summary != "receiving 10s, prepare" {
+		t.Errorf("unexpected summary for an ongoing phase: %s", summary)
+	}
+
+	sst.EndPhases(at(15))
+	if summary := sst.PhasesSummary(); summary != "receiving 10s, prepare 5s" {
+		t.Errorf("unexpected summary: %s", summary)
+	}
+
+	// the script ended, this is a new SST
+	sst.StartPhase(SSTPhaseStreaming, at(20))
+	if len(sst.Phases) != 1 || sst.CurrentPhase() != SSTPhaseStreaming {
+		t.Errorf("expected the phases of the previous SST to be dropped, got %v", sst.Phases)
+	}
+}
+
+func TestSSTPhasesCopied(t *testing.T) {
+	at := func(sec int) *Date {
+		return NewDate(time.Date(2001, 1, 1, 1, 1, sec, 0, time.UTC), "")
+	}
+
+	sst := SST{}
+	sst.StartPhase(SSTPhaseReceiving, at(0))
+	receiving := sst
+	sst.StartPhase(SSTPhasePrepare, at(10))
+	prepare := sst
+	sst.EndPhases(at(12))
+
+	if !receiving.Ongoing() || receiving.CurrentPhase() != SSTPhaseReceiving {
+		t.Errorf("the context during receiving saw it end: %+v", receiving.Phases)
+	}
+	if !prepare.Ongoing() || prepare.CurrentPhase() != SSTPhasePrepare {
+		t.Errorf("the context during prepare saw it end: %+v", prepare.Phases)
+	}
+	if sst.Ongoing() {
+		t.Errorf("expected every phase to be ended: %+v", sst.Phases)
+	}
+}
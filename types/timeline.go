@@ -2,7 +2,6 @@ package types
 
 import (
 	"math"
-	"path/filepath"
 	"time"
 )
 
@@ -39,9 +38,9 @@ func (timeline Timeline) MergeByIdentifier(lt LocalTimeline) {
 }
 
 func (timeline Timeline) MergeByDirectory(path string, lt LocalTimeline) {
-	node := filepath.Base(filepath.Dir(path))
+	node := DirectoryIdentifier(path)
 	for _, lt2 := range timeline {
-		if len(lt2) > 0 && node == filepath.Base(filepath.Dir(lt2[0].Ctx.FilePath)) {
+		if len(lt2) > 0 && node == DirectoryIdentifier(lt2[0].Ctx.FilePath) {
 			lt = MergeTimeline(lt2, lt)
 		}
 	}
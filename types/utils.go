@@ -1,6 +1,9 @@
 package types
 
 import (
+	"net"
+	"path/filepath"
+
 	"github.com/rs/zerolog/log"
 	"github.com/ylacancellera/galera-log-explainer/utils"
 )
@@ -14,6 +17,10 @@ func Identifier(ctx LogCtx) string {
 	if len(ctx.OwnNames) > 0 {
 		return ctx.OwnNames[len(ctx.OwnNames)-1]
 	}
+	// given by syslog or journald
+	if ctx.Hostname != "" {
+		return ctx.Hostname
+	}
 	if len(ctx.OwnIPs) > 0 {
 		return DisplayNodeSimplestForm(ctx, ctx.OwnIPs[len(ctx.OwnIPs)-1])
 	}
@@ -28,6 +35,11 @@ func Identifier(ctx LogCtx) string {
 	return ctx.FilePath
 }
 
+// DirectoryIdentifier is the identifier used when merging by directory: the base directory of the file
+func DirectoryIdentifier(path string) string {
+	return filepath.Base(filepath.Dir(path))
+}
+
 // DisplayNodeSimplestForm is useful to get the most easily to read string for a given IP
 // This only has impacts on display
 // In order of preference: wsrep_node_name (or galera "node" name), hostname, ip
@@ -54,3 +66,22 @@ func DisplayNodeSimplestForm(ctx LogCtx, ip string) string {
 	log.Debug().Str("ip", ip).Str("simplestform", ip).Str("from", "default").Msg("nodeSimplestForm")
 	return ip
 }
+
+// DisplayHashSimplestForm is the same as DisplayNodeSimplestForm, for node hashes
+func DisplayHashSimplestForm(ctx LogCtx, hash string) string {
+	if nodename, ok := ctx.HashToNodeName[hash]; ok {
+		return utils.ShortNodeName(nodename)
+	}
+	if ip, ok := ctx.HashToIP[hash]; ok {
+		return DisplayNodeSimplestForm(ctx, ip)
+	}
+	return hash
+}
+
+// DisplayPeerSimplestForm is for peers known either by their ip or by their hash
+func DisplayPeerSimplestForm(ctx LogCtx, peer string) string {
+	if net.ParseIP(peer) != nil {
+		return DisplayNodeSimplestForm(ctx, peer)
+	}
+	return DisplayHashSimplestForm(ctx, peer)
+}
@@ -0,0 +1,186 @@
+package types
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ylacancellera/galera-log-explainer/utils"
+)
+
+// View is a galera view, as installed by a single node
+type View struct {
+	ID              string       `json:"id" yaml:"id"`                             // state id, "cluster uuid:seqno". Every members of a view share it
+	Number          string       `json:"number,omitempty" yaml:"number,omitempty"` // view#, only given by galera 3 "New cluster view"
+	Date            *Date        `json:"date,omitempty" yaml:"date,omitempty"`
+	Status          string       `json:"status" yaml:"status"` // primary, non-primary
+	ProtocolVersion string       `json:"protocolVersion" yaml:"protocolVersion"`
+	OwnIndex        string       `json:"ownIndex" yaml:"ownIndex"`
+	MemberCount     int          `json:"memberCount" yaml:"memberCount"`
+	Members         []ViewMember `json:"members,omitempty" yaml:"members,omitempty"` // ordered as galera gave them, empty for galera 3
+}
+
+type ViewMember struct {
+	UUID string `json:"uuid" yaml:"uuid"`
+	Name string `json:"name" yaml:"name"`
+}
+
+// Key identifies a view cluster-wide
+// non-primary views all share the same seqno (-1), they can only be told apart by their members
+func (v View) Key() string {
+	if v.Number != "" && v.Number != "-1" {
+		return "view#" + v.Number
+	}
+	if v.Status == "primary" {
+		return v.ID
+	}
+	return v.ID + " " + v.membersKey()
+}
+
+func (v View) membersKey() string {
+	uuids := []string{}
+	for _, m := range v.Members {
+		uuids = append(uuids, m.UUID)
+	}
+	return strings.Join(uuids, ",")
+}
+
+// agreesWith tells if both nodes installed the very same view
+func (v View) agreesWith(v2 View) bool {
+	if v.Status != v2.Status || v.MemberCount != v2.MemberCount {
+		return false
+	}
+	// galera 3 does not give the list of members
+	if len(v.Members) == 0 || len(v2.Members) == 0 {
+		return true
+	}
+	return v.membersKey() == v2.membersKey()
+}
+
+type Views []View
+
+// Add appends a view to the history
+// A same view can be described by several logs: the latest one completes the first
+func (vs Views) Add(v View) Views {
+	if len(vs) > 0 && vs[len(vs)-1].ID == v.ID && vs[len(vs)-1].Key() == v.Key() {
+		last := vs[len(vs)-1]
+		if len(v.Members) == 0 {
+			v.Members = last.Members
+		}
+		if v.Number == "" {
+			v.Number = last.Number
+		}
+		if v.Date == nil {
+			v.Date = last.Date
+		}
+		// copied so that contexts from previous events are not modified
+		return append(vs[:len(vs)-1:len(vs)-1], v)
+	}
+	return append(vs, v)
+}
+
+// ClusterView is a single view, reconciled from what every node installed
+type ClusterView struct {
+	Key      string          `json:"key" yaml:"key"`
+	Date     *Date           `json:"date,omitempty" yaml:"date,omitempty"` // earliest one
+	Expected View            `json:"expected" yaml:"expected"`             // what most nodes installed
+	PerNode  map[string]View `json:"perNode" yaml:"perNode"`
+	Disagree []string        `json:"disagree,omitempty" yaml:"disagree,omitempty"` // nodes that installed a different view
+	Missing  []string        `json:"missing,omitempty" yaml:"missing,omitempty"`   // nodes seen in other views, but not part of this one
+}
+
+// ClusterViews groups the view histories of each node, sorted chronologically
+func ClusterViews(ctxs map[string]LogCtx) []ClusterView {
+	byKey := map[string]*ClusterView{}
+	order := []*ClusterView{}
+
+	nodes := []string{}
+	for node := range ctxs {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	for _, node := range nodes {
+		for _, v := range ctxs[node].Views {
+			cv, ok := byKey[v.Key()]
+			if !ok {
+				cv = &ClusterView{Key: v.Key(), PerNode: map[string]View{}}
+				byKey[v.Key()] = cv
+				order = append(order, cv)
+			}
+			cv.PerNode[node] = v
+			if v.Date != nil && (cv.Date == nil || v.Date.Time.Before(cv.Date.Time)) {
+				cv.Date = v.Date
+			}
+		}
+	}
+
+	views := []ClusterView{}
+	names := []string{}
+	for _, cv := range order {
+		cv.reconcile(nodes)
+		for _, m := range cv.Expected.Members {
+			if !utils.SliceContains(names, m.Name) {
+				names = append(names, m.Name)
+			}
+		}
+		views = append(views, *cv)
+	}
+	for i := range views {
+		views[i].setMissing(names)
+	}
+	sort.SliceStable(views, func(i, j int) bool {
+		if views[i].Date == nil || views[j].Date == nil {
+			return views[j].Date == nil && views[i].Date != nil
+		}
+		return views[i].Date.Time.Before(views[j].Date.Time)
+	})
+	return views
+}
+
+func (cv *ClusterView) reconcile(nodes []string) {
+
+	// the expected view is the most common one, first node alphabetically on ties
+	best := 0
+	for _, node := range nodes {
+		v, ok := cv.PerNode[node]
+		if !ok {
+			continue
+		}
+		count := 0
+		for _, v2 := range cv.PerNode {
+			if v.agreesWith(v2) {
+				count++
+			}
+		}
+		if count > best {
+			best = count
+			cv.Expected = v
+		}
+	}
+
+	for _, node := range nodes {
+		v, ok := cv.PerNode[node]
+		if ok && !v.agreesWith(cv.Expected) {
+			cv.Disagree = append(cv.Disagree, node)
+		}
+	}
+}
+
+// galera 3 views do not have members, we can't tell who is missing
+func (cv *ClusterView) setMissing(names []string) {
+	if len(cv.Expected.Members) == 0 {
+		return
+	}
+	for _, name := range names {
+		found := false
+		for _, m := range cv.Expected.Members {
+			if m.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			cv.Missing = append(cv.Missing, name)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClusterViews(t *testing.T) {
+
+	node1 := ViewMember{UUID: "015702fc-32f5-11ed-a4ca-267f97316394", Name: "node1"}
+	node2 := ViewMember{UUID: "08dd5580-32f7-11ed-a9eb-af5e3d01519e", Name: "node2"}
+	node3 := ViewMember{UUID: "1a4c7e2e-32f7-11ed-b0f1-8b5d3e7b6c11", Name: "node3"}
+	date1 := &Date{Time: time.Date(2023, time.January, 1, 1, 1, 1, 1, time.UTC)}
+	date2 := &Date{Time: time.Date(2023, time.January, 2, 1, 1, 1, 1, time.UTC)}
+
+	tests := []struct {
+		name     string
+		input    map[string]LogCtx
+		expected []ClusterView
+	}{
+		{
+			name: "same view from 2 nodes, with a third node having left",
+			input: map[string]LogCtx{
+				"node1": LogCtx{Views: Views{
+					View{ID: "uuid:10", Date: date1, Status: "primary", OwnIndex: "0", MemberCount: 3, Members: []ViewMember{node1, node2, node3}},
+					View{ID: "uuid:20", Date: date2, Status: "primary", OwnIndex: "0", MemberCount: 2, Members: []ViewMember{node1, node2}},
+				}},
+				"node2": LogCtx{Views: Views{
+					View{ID: "uuid:20", Date: date2, Status: "primary", OwnIndex: "1", MemberCount: 2, Members: []ViewMember{node1, node2}},
+				}},
+			},
+			expected: []ClusterView{
+				{
+					Key:      "uuid:10",
+					Date:     date1,
+					Expected: View{ID: "uuid:10", Date: date1, Status: "primary", OwnIndex: "0", MemberCount: 3, Members: []ViewMember{node1, node2, node3}},
+					PerNode: map[string]View{
+						"node1": View{ID: "uuid:10", Date: date1, Status: "primary", OwnIndex: "0", MemberCount: 3, Members: []ViewMember{node1, node2, node3}},
+					},
+				},
+				{
+					Key:      "uuid:20",
+					Date:     date2,
+					Expected: View{ID: "uuid:20", Date: date2, Status: "primary", OwnIndex: "0", MemberCount: 2, Members: []ViewMember{node1, node2}},
+					PerNode: map[string]View{
+						"node1": View{ID: "uuid:20", Date: date2, Status: "primary", OwnIndex: "0", MemberCount: 2, Members: []ViewMember{node1, node2}},
+						"node2": View{ID: "uuid:20", Date: date2, Status: "primary", OwnIndex: "1", MemberCount: 2, Members: []ViewMember{node1, node2}},
+					},
+					Missing: []string{"node3"},
+				},
+			},
+		},
+		{
+			name: "a node disagrees",
+			input: map[string]LogCtx{
+				"node1": LogCtx{Views: Views{
+					View{ID: "uuid:10", Number: "5", Date: date1, Status: "primary", MemberCount: 3},
+				}},
+				"node2": LogCtx{Views: Views{
+					View{ID: "uuid:10", Number: "5", Date: date2, Status: "primary", MemberCount: 3},
+				}},
+				"node3": LogCtx{Views: Views{
+					View{ID: "uuid:10", Number: "5", Date: date1, Status: "primary", MemberCount: 2},
+				}},
+			},
+			expected: []ClusterView{
+				{
+					Key:      "view#5",
+					Date:     date1,
+					Expected: View{ID: "uuid:10", Number: "5", Date: date1, Status: "primary", MemberCount: 3},
+					PerNode: map[string]View{
+						"node1": View{ID: "uuid:10", Number: "5", Date: date1, Status: "primary", MemberCount: 3},
+						"node2": View{ID: "uuid:10", Number: "5", Date: date2, Status: "primary", MemberCount: 3},
+						"node3": View{ID: "uuid:10", Number: "5", Date: date1, Status: "primary", MemberCount: 2},
+					},
+					Disagree: []string{"node3"},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		out := ClusterViews(test.input)
+		if !reflect.DeepEqual(out, test.expected) {
+			t.Errorf("testname: %s, expected: \n%#v\n got: \n%#v", test.name, test.expected, out)
+		}
+	}
+}
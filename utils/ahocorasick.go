@@ -0,0 +1,82 @@
+package utils
+
+// AhoCorasick searches for many literal patterns at once, in a single pass over the input
+// It is used to prefilter log lines: it tells which literals are present so that
+// we only execute the few regexes that have a chance to match
+type AhoCorasick struct {
+	// complete automaton: every state has a transition for every byte
+	// so that searching never has to follow failure links
+	next    [][256]int32
+	outputs [][]int
+}
+
+// NewAhoCorasick builds the automaton. Patterns are identified by their index in the slice
+func NewAhoCorasick(patterns []string) *AhoCorasick {
+	ac := &AhoCorasick{}
+	ac.addState()
+
+	// trie
+	for i, pattern := range patterns {
+		state := int32(0)
+		for j := 0; j < len(pattern); j++ {
+			c := pattern[j]
+			if ac.next[state][c] == -1 {
+				ac.next[state][c] = ac.addState()
+			}
+			state = ac.next[state][c]
+		}
+		ac.outputs[state] = append(ac.outputs[state], i)
+	}
+
+	// failure links, computed breadth-first
+	fail := make([]int32, len(ac.next))
+	queue := []int32{}
+	for c := 0; c < 256; c++ {
+		if s := ac.next[0][c]; s != -1 {
+			fail[s] = 0
+			queue = append(queue, s)
+		} else {
+			ac.next[0][c] = 0
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		ac.outputs[state] = append(ac.outputs[state], ac.outputs[fail[state]]...)
+
+		for c := 0; c < 256; c++ {
+			s := ac.next[state][c]
+			if s == -1 {
+				ac.next[state][c] = ac.next[fail[state]][c]
+				continue
+			}
+			fail[s] = ac.next[fail[state]][c]
+			queue = append(queue, s)
+		}
+	}
+	return ac
+}
+
+func (ac *AhoCorasick) addState() int32 {
+	var transitions [256]int32
+	for i := range transitions {
+		transitions[i] = -1
+	}
+	ac.next = append(ac.next, transitions)
+	ac.outputs = append(ac.outputs, nil)
+	return int32(len(ac.next) - 1)
+}
+
+// Search calls found for each pattern occurence in s, in the order they end
+// The same pattern can be reported several times. Returning false from found stops the search
+func (ac *AhoCorasick) Search(s string, found func(pattern int) bool) {
+	state := int32(0)
+	for i := 0; i < len(s); i++ {
+		state = ac.next[state][s[i]]
+		for _, pattern := range ac.outputs[state] {
+			if !found(pattern) {
+				return
+			}
+		}
+	}
+}
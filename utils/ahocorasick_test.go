@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAhoCorasick(t *testing.T) {
+	ac := NewAhoCorasick([]string{"he", "she", "his", "hers"})
+
+	tests := []struct {
+		input    string
+		expected []int
+	}{
+		{input: "ushers", expected: []int{1, 0, 3}},
+		{input: "this", expected: []int{2}},
+		{input: "nothing", expected: nil},
+		{input: "hehe", expected: []int{0, 0}},
+	}
+
+	for _, test := range tests {
+		var out []int
+		ac.Search(test.input, func(pattern int) bool {
+			out = append(out, pattern)
+			return true
+		})
+		if !reflect.DeepEqual(out, test.expected) {
+			t.Errorf("input: %s, expected: %v, got: %v", test.input, test.expected, out)
+		}
+	}
+
+	// stopping early
+	count := 0
+	ac.Search("ushers", func(pattern int) bool {
+		count++
+		return false
+	})
+	if count != 1 {
+		t.Errorf("search should have stopped after the first pattern, got %d calls", count)
+	}
+}
@@ -121,3 +121,17 @@ func ShortNodeName(s string) string {
 	before, _, _ := strings.Cut(s, ".")
 	return before
 }
+
+// HumanBytes is meant for write set sizes, they are logged in bytes
+func HumanBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%dB", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
@@ -40,3 +40,22 @@ func TestStringsReplaceReverse(t *testing.T) {
 		}
 	}
 }
+
+func TestHumanBytes(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{input: 0, expected: "0B"},
+		{input: 1023, expected: "1023B"},
+		{input: 1024, expected: "1.0KiB"},
+		{input: 1536, expected: "1.5KiB"},
+		{input: 2147483648, expected: "2.0GiB"},
+	}
+
+	for _, test := range tests {
+		if out := HumanBytes(test.input); out != test.expected {
+			t.Errorf("%d: expected %s, got %s", test.input, test.expected, out)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ylacancellera/galera-log-explainer/regex"
+	"github.com/ylacancellera/galera-log-explainer/types"
+	"github.com/ylacancellera/galera-log-explainer/utils"
+	"gopkg.in/yaml.v2"
+)
+
+type views struct {
+	Paths []string `arg:"" name:"paths" help:"paths of the log to use"`
+	Yaml  bool     `xor:"format"`
+	Json  bool     `xor:"format"`
+}
+
+func (v *views) Help() string {
+	return `Show the sequence of views installed in the cluster, with their members
+	The same view seen from every nodes are reconciled, nodes that installed a different one are highlighted
+
+Usage:
+	galera-log-explainer views *.log
+	galera-log-explainer views --json *.log
+	`
+}
+
+func (v *views) Run() error {
+
+	regexes := regex.IdentsMap.Merge(regex.ViewsMap)
+	timeline, err := timelineFromPaths(v.Paths, regexes)
+	if err != nil {
+		return err
+	}
+
+	clusterViews := types.ClusterViews(timeline.GetLatestUpdatedContextsByNodes())
+
+	var out string
+	if v.Yaml {
+		tmp, err := yaml.Marshal(clusterViews)
+		if err != nil {
+			return err
+		}
+		out = string(tmp)
+	} else if v.Json {
+		tmp, err := json.Marshal(clusterViews)
+		if err != nil {
+			return err
+		}
+		out = string(tmp)
+	} else {
+		for _, cv := range clusterViews {
+			out += "\n"
+			out += "\n" + utils.Paint(utils.BlueText, "view: ") + cv.Key
+			if cv.Date != nil {
+				out += " at " + cv.Date.DisplayTime
+			}
+			out += "\n\t" + utils.Paint(utils.BlueText, "status: ") + displayViewStatus(cv.Expected)
+			if len(cv.Expected.Members) > 0 {
+				out += "\n\t" + utils.Paint(utils.BlueText, "members: ") + displayViewMembers(cv.Expected)
+			}
+			if len(cv.Missing) > 0 {
+				out += "\n\t" + utils.Paint(utils.BlueText, "missing: ") + utils.Paint(utils.YellowText, strings.Join(cv.Missing, ", "))
+			}
+
+			nodes := []string{}
+			for node := range cv.PerNode {
+				nodes = append(nodes, node)
+			}
+			sort.Strings(nodes)
+			out += "\n\t" + utils.Paint(utils.BlueText, "seen by: ") + strings.Join(nodes, ", ")
+
+			for _, node := range cv.Disagree {
+				nodeView := cv.PerNode[node]
+				out += "\n\t" + utils.Paint(utils.RedText, node+" disagrees: ") + displayViewStatus(nodeView)
+				if len(nodeView.Members) > 0 {
+					out += ", " + displayViewMembers(nodeView)
+				}
+			}
+		}
+	}
+	fmt.Println(out)
+	return nil
+}
+
+func displayViewStatus(view types.View) string {
+	status := utils.Paint(utils.RedText, view.Status)
+	if view.Status == "primary" {
+		status = utils.Paint(utils.GreenText, view.Status)
+	}
+	return status + "(n=" + strconv.Itoa(view.MemberCount) + ")"
+}
+
+func displayViewMembers(view types.View) string {
+	names := []string{}
+	for _, m := range view.Members {
+		names = append(names, m.Name)
+	}
+	return strings.Join(names, ", ")
+}